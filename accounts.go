@@ -2,34 +2,41 @@ package lendingclub
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/shopspring/decimal"
 )
 
 const (
-	accountsResourcePath  = "/accounts/%d"
-	summaryEndpoint       = "/summary"
-	availableCashEndpoint = "/availablecash"
-	addFundsEndpoint      = "/funds/add"
-	withdrawFundsEndpoint = "/funds/withdraw"
-	pendingFundsEndpoint  = "/funds/pending"
-	cancelFundsEndpoint   = "/funds/cancel"
-	notesEndpoint         = "/notes"
-	portfoliosEndpoint    = "/portfolios"
-	ordersEndpoint        = "/orders"
+	accountsResourcePath   = "/accounts/%d"
+	summaryEndpoint        = "/summary"
+	availableCashEndpoint  = "/availablecash"
+	addFundsEndpoint       = "/funds/add"
+	withdrawFundsEndpoint  = "/funds/withdraw"
+	pendingFundsEndpoint   = "/funds/pending"
+	cancelFundsEndpoint    = "/funds/cancel"
+	notesEndpoint          = "/notes"
+	detailedNotesEndpoint  = "/detailednotes"
+	portfoliosEndpoint     = "/portfolios"
+	portfolioEndpoint      = "/portfolios/%d"
+	portfolioNotesEndpoint = "/portfolios/%d/notes"
+	ordersEndpoint         = "/orders"
 )
 
 type AccountsResource struct {
-	client   *Client
-	endpoint string
+	client     *Client
+	endpoint   string
+	investorID int
 }
 
 func (c *Client) Accounts(investorID int) *AccountsResource {
 	return &AccountsResource{
-		client:   c,
-		endpoint: fmt.Sprintf(c.baseURL+accountsResourcePath, investorID),
+		client:     c,
+		endpoint:   fmt.Sprintf(c.baseURL+accountsResourcePath, investorID),
+		investorID: investorID,
 	}
 }
 
@@ -39,7 +46,11 @@ type AvailableCash struct {
 }
 
 func (ar *AccountsResource) AvailableCash() (*AvailableCash, error) {
-	req, err := ar.client.newRequest("GET", ar.endpoint+availableCashEndpoint, nil)
+	return ar.AvailableCashContext(context.Background())
+}
+
+func (ar *AccountsResource) AvailableCashContext(ctx context.Context) (*AvailableCash, error) {
+	req, err := ar.client.newRequest(ctx, "GET", ar.endpoint+availableCashEndpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +81,11 @@ type Summary struct {
 }
 
 func (ar *AccountsResource) Summary() (*Summary, error) {
-	req, err := ar.client.newRequest("GET", ar.endpoint+summaryEndpoint, nil)
+	return ar.SummaryContext(context.Background())
+}
+
+func (ar *AccountsResource) SummaryContext(ctx context.Context) (*Summary, error) {
+	req, err := ar.client.newRequest(ctx, "GET", ar.endpoint+summaryEndpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -87,26 +102,41 @@ func (ar *AccountsResource) Summary() (*Summary, error) {
 }
 
 type FundsPayload struct {
-	Amount            decimal.Decimal `json:"amount"`
-	TransferFrequency string          `json:"transferFrequency"`
-	StartDate         *Time           `json:"startDate,omitempty"`
-	EndDate           *Time           `json:"endDate,omitempty"`
+	Amount            decimal.Decimal   `json:"amount"`
+	TransferFrequency TransferFrequency `json:"transferFrequency"`
+	StartDate         *Time             `json:"startDate,omitempty"`
+	EndDate           *Time             `json:"endDate,omitempty"`
 }
 
 type Deposit struct {
 	FundsPayload
-	InvestorID                 int    `json:"investorId"`
-	Frequency                  string `json:"frequency"`
-	EstimatedFundsTransferDate Time   `json:"estimatedFundsTransferDate"`
+	InvestorID                 int               `json:"investorId"`
+	Frequency                  TransferFrequency `json:"frequency"`
+	EstimatedFundsTransferDate Time              `json:"estimatedFundsTransferDate"`
 }
 
 func (ar *AccountsResource) AddFunds(fundTransfer *FundsPayload) (*Deposit, error) {
+	return ar.AddFundsContext(context.Background(), fundTransfer)
+}
+
+func (ar *AccountsResource) AddFundsContext(ctx context.Context, fundTransfer *FundsPayload) (*Deposit, error) {
+	if fundTransfer != nil {
+		if err := fundTransfer.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	payload, err := json.Marshal(fundTransfer)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := ar.client.newRequest("POST", ar.endpoint+addFundsEndpoint, bytes.NewBuffer(payload))
+	if ar.client.dryRun {
+		ar.client.logDryRun("POST", ar.endpoint+addFundsEndpoint, payload)
+		return simulateDeposit(ar.investorID, fundTransfer)
+	}
+
+	req, err := ar.client.newRequest(ctx, "POST", ar.endpoint+addFundsEndpoint, bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, err
 	}
@@ -129,6 +159,10 @@ type Withdrawal struct {
 }
 
 func (ar *AccountsResource) WithdrawFunds(amount decimal.Decimal) (*Withdrawal, error) {
+	return ar.WithdrawFundsContext(context.Background(), amount)
+}
+
+func (ar *AccountsResource) WithdrawFundsContext(ctx context.Context, amount decimal.Decimal) (*Withdrawal, error) {
 	withdrawal := struct {
 		Amount decimal.Decimal `json:"amount"`
 	}{
@@ -139,7 +173,12 @@ func (ar *AccountsResource) WithdrawFunds(amount decimal.Decimal) (*Withdrawal,
 		return nil, err
 	}
 
-	req, err := ar.client.newRequest("POST", ar.endpoint+withdrawFundsEndpoint, bytes.NewBuffer(payload))
+	if ar.client.dryRun {
+		ar.client.logDryRun("POST", ar.endpoint+withdrawFundsEndpoint, payload)
+		return simulateWithdrawal(ar.investorID, amount)
+	}
+
+	req, err := ar.client.newRequest(ctx, "POST", ar.endpoint+withdrawFundsEndpoint, bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, err
 	}
@@ -156,19 +195,25 @@ func (ar *AccountsResource) WithdrawFunds(amount decimal.Decimal) (*Withdrawal,
 }
 
 type Transfer struct {
-	TransferID    int             `json:"transferId"`
-	TransferDate  Time            `json:"transferDate"`
-	Amount        decimal.Decimal `json:"amount"`
-	SourceAccount string          `json:"sourceAccount"`
-	Status        string          `json:"status"`
-	Frequency     string          `json:"frequency"`
-	EndDate       Time            `json:"endDate"`
-	Operation     string          `json:"operation"`
-	Cancellable   bool            `json:"cancellable"`
+	TransferID    int               `json:"transferId"`
+	TransferDate  Time              `json:"transferDate"`
+	Amount        decimal.Decimal   `json:"amount"`
+	SourceAccount string            `json:"sourceAccount"`
+	Status        TransferStatus    `json:"status"`
+	Frequency     TransferFrequency `json:"frequency"`
+	EndDate       Time              `json:"endDate"`
+	Operation     TransferOperation `json:"operation"`
+	Cancellable   bool              `json:"cancellable"`
+}
+
+// PendingFunds returns the pending transfers matching all filters, ordered
+// by TransferDate.
+func (ar *AccountsResource) PendingFunds(filters ...TransferFilter) ([]Transfer, error) {
+	return ar.PendingFundsContext(context.Background(), filters...)
 }
 
-func (ar *AccountsResource) PendingFunds() ([]Transfer, error) {
-	req, err := ar.client.newRequest("GET", ar.endpoint+pendingFundsEndpoint, nil)
+func (ar *AccountsResource) PendingFundsContext(ctx context.Context, filters ...TransferFilter) ([]Transfer, error) {
+	req, err := ar.client.newRequest(ctx, "GET", ar.endpoint+pendingFundsEndpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -185,11 +230,21 @@ func (ar *AccountsResource) PendingFunds() ([]Transfer, error) {
 		return nil, err
 	}
 
-	transfers := make([]Transfer, len(respPayload.Transfers))
+	transfers := make([]Transfer, 0, len(respPayload.Transfers))
 	for _, transfer := range respPayload.Transfers {
-		transfers = append(transfers, transfer)
+		if matchTransfer(transfer, filters) {
+			transfers = append(transfers, transfer)
+		}
 	}
 
+	sort.Slice(transfers, func(i, j int) bool {
+		ti, tj := transfers[i].TransferDate.Time, transfers[j].TransferDate.Time
+		if ti.Equal(tj) {
+			return transfers[i].TransferID < transfers[j].TransferID
+		}
+		return ti.Before(tj)
+	})
+
 	return transfers, nil
 }
 
@@ -205,6 +260,10 @@ type Cancellation struct {
 }
 
 func (ar *AccountsResource) CancelFunds(transferIds []int) (*CancellationResult, error) {
+	return ar.CancelFundsContext(context.Background(), transferIds)
+}
+
+func (ar *AccountsResource) CancelFundsContext(ctx context.Context, transferIds []int) (*CancellationResult, error) {
 	transfers := struct {
 		TransferIDs []int `json:"transferIds"`
 	}{
@@ -215,7 +274,12 @@ func (ar *AccountsResource) CancelFunds(transferIds []int) (*CancellationResult,
 		return nil, err
 	}
 
-	req, err := ar.client.newRequest("POST", ar.endpoint+cancelFundsEndpoint, bytes.NewBuffer(payload))
+	if ar.client.dryRun {
+		ar.client.logDryRun("POST", ar.endpoint+cancelFundsEndpoint, payload)
+		return simulateCancellation(ar.investorID, transferIds)
+	}
+
+	req, err := ar.client.newRequest(ctx, "POST", ar.endpoint+cancelFundsEndpoint, bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, err
 	}
@@ -237,8 +301,8 @@ type Note struct {
 	LoanID           decimal.Decimal `json:"loanId"`
 	OrderID          decimal.Decimal `json:"orderId"`
 	InterestRate     decimal.Decimal `json:"interestRate"`
-	LoanStatus       string          `json:"loanStatus"`
-	Grade            string          `json:"grade"`
+	LoanStatus       LoanStatus      `json:"loanStatus"`
+	Grade            SubGrade        `json:"grade"`
 	LoanAmount       decimal.Decimal `json:"loanAmount"`
 	LoanLength       int             `json:"loanLength"`
 	OrderDate        Time            `json:"orderDate"`
@@ -248,9 +312,12 @@ type Note struct {
 	LoanStatusDate Time `json:"loanStatusDate"`
 }
 
-// TODO: Detailed Notes Owned
 func (ar *AccountsResource) Notes() ([]Note, error) {
-	req, err := ar.client.newRequest("GET", ar.endpoint+notesEndpoint, nil)
+	return ar.NotesContext(context.Background())
+}
+
+func (ar *AccountsResource) NotesContext(ctx context.Context) ([]Note, error) {
+	req, err := ar.client.newRequest(ctx, "GET", ar.endpoint+notesEndpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -268,6 +335,65 @@ func (ar *AccountsResource) Notes() ([]Note, error) {
 	return myNotes.Notes, err
 }
 
+// DetailedNote is a note owned by the investor as returned by the Detailed
+// Notes Owned endpoint. Dates that are not known yet, such as the next
+// payment date of a note in funding, are nil.
+type DetailedNote struct {
+	ID                    decimal.Decimal `json:"noteId"`
+	LoanID                decimal.Decimal `json:"loanId"`
+	OrderID               decimal.Decimal `json:"orderId"`
+	PortfolioID           int             `json:"portfolioId"`
+	PortfolioName         string          `json:"portfolioName"`
+	Amount                decimal.Decimal `json:"noteAmount"`
+	LoanAmount            decimal.Decimal `json:"loanAmount"`
+	LoanLength            int             `json:"loanLength"`
+	InterestRate          decimal.Decimal `json:"interestRate"`
+	Grade                 SubGrade        `json:"grade"`
+	Purpose               Purpose         `json:"purpose"`
+	ApplicationType       ApplicationType `json:"applicationType"`
+	LoanStatus            LoanStatus      `json:"loanStatus"`
+	CurrentPaymentStatus  string          `json:"currentPaymentStatus"`
+	CreditTrend           string          `json:"creditTrend"`
+	CanBeTraded           bool            `json:"canBeTraded"`
+	CanUseTruncatedSearch bool            `json:"canUseTruncatedSearch"`
+	IsInBankruptcy        bool            `json:"isInBankruptcy"`
+	PaymentsReceived      decimal.Decimal `json:"paymentsReceived"`
+	PrincipalReceived     decimal.Decimal `json:"principalReceived"`
+	InterestReceived      decimal.Decimal `json:"interestReceived"`
+	LateFeesReceived      decimal.Decimal `json:"lateFeesReceived"`
+	PrincipalPending      decimal.Decimal `json:"principalPending"`
+	InterestPending       decimal.Decimal `json:"interestPending"`
+	AccruedInterest       decimal.Decimal `json:"accruedInterest"`
+	OrderDate             Time            `json:"orderDate"`
+	IssueDate             *Time           `json:"issueDate"`
+	LoanStatusDate        *Time           `json:"loanStatusDate"`
+	LastPaymentDate       *Time           `json:"lastPaymentDate"`
+	NextPaymentDate       *Time           `json:"nextPaymentDate"`
+}
+
+func (ar *AccountsResource) DetailedNotes() ([]DetailedNote, error) {
+	return ar.DetailedNotesContext(context.Background())
+}
+
+func (ar *AccountsResource) DetailedNotesContext(ctx context.Context) ([]DetailedNote, error) {
+	req, err := ar.client.newRequest(ctx, "GET", ar.endpoint+detailedNotesEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := ar.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var myNotes struct {
+		Notes []DetailedNote `json:"myNotes"`
+	}
+	err = ar.client.processResponse(res, &myNotes)
+
+	return myNotes.Notes, err
+}
+
 type Portfolio struct {
 	ID          int    `json:"portfolioId,omitempty"`
 	Name        string `json:"portfolioName"`
@@ -275,7 +401,11 @@ type Portfolio struct {
 }
 
 func (ar *AccountsResource) Portfolios() ([]Portfolio, error) {
-	req, err := ar.client.newRequest("GET", ar.endpoint+portfoliosEndpoint, nil)
+	return ar.PortfoliosContext(context.Background())
+}
+
+func (ar *AccountsResource) PortfoliosContext(ctx context.Context) ([]Portfolio, error) {
+	req, err := ar.client.newRequest(ctx, "GET", ar.endpoint+portfoliosEndpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -294,12 +424,55 @@ func (ar *AccountsResource) Portfolios() ([]Portfolio, error) {
 }
 
 func (ar *AccountsResource) CreatePortfolio(name, description string) (*Portfolio, error) {
+	return ar.CreatePortfolioContext(context.Background(), name, description)
+}
+
+func (ar *AccountsResource) CreatePortfolioContext(ctx context.Context, name, description string) (*Portfolio, error) {
+	payload, err := json.Marshal(Portfolio{Name: name, Description: description})
+	if err != nil {
+		return nil, err
+	}
+
+	if ar.client.dryRun {
+		ar.client.logDryRun("POST", ar.endpoint+portfoliosEndpoint, payload)
+		return simulatePortfolio(ar.client.nextDryRunID(), name, description)
+	}
+
+	req, err := ar.client.newRequest(ctx, "POST", ar.endpoint+portfoliosEndpoint, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := ar.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var portfolio Portfolio
+	err = ar.client.processResponse(res, &portfolio)
+
+	return &portfolio, err
+}
+
+// UpdatePortfolio renames the portfolio with the given ID and replaces its
+// description.
+func (ar *AccountsResource) UpdatePortfolio(portfolioID int, name, description string) (*Portfolio, error) {
+	return ar.UpdatePortfolioContext(context.Background(), portfolioID, name, description)
+}
+
+func (ar *AccountsResource) UpdatePortfolioContext(ctx context.Context, portfolioID int, name, description string) (*Portfolio, error) {
 	payload, err := json.Marshal(Portfolio{Name: name, Description: description})
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := ar.client.newRequest("POST", ar.endpoint+portfoliosEndpoint, bytes.NewBuffer(payload))
+	endpoint := ar.endpoint + fmt.Sprintf(portfolioEndpoint, portfolioID)
+	if ar.client.dryRun {
+		ar.client.logDryRun("PUT", endpoint, payload)
+		return simulatePortfolio(portfolioID, name, description)
+	}
+
+	req, err := ar.client.newRequest(ctx, "PUT", endpoint, bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, err
 	}
@@ -315,6 +488,96 @@ func (ar *AccountsResource) CreatePortfolio(name, description string) (*Portfoli
 	return &portfolio, err
 }
 
+// DeletePortfolio deletes the portfolio with the given ID. Notes held in it
+// are not sold.
+func (ar *AccountsResource) DeletePortfolio(portfolioID int) error {
+	return ar.DeletePortfolioContext(context.Background(), portfolioID)
+}
+
+func (ar *AccountsResource) DeletePortfolioContext(ctx context.Context, portfolioID int) error {
+	endpoint := ar.endpoint + fmt.Sprintf(portfolioEndpoint, portfolioID)
+	if ar.client.dryRun {
+		ar.client.logDryRun("DELETE", endpoint, nil)
+		return nil
+	}
+
+	req, err := ar.client.newRequest(ctx, "DELETE", endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := ar.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	return ar.client.processResponse(res, nil)
+}
+
+type NoteAssignment struct {
+	NoteID  decimal.Decimal `json:"noteId"`
+	OrderID decimal.Decimal `json:"orderId"`
+	LoanID  decimal.Decimal `json:"loanId"`
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+}
+
+// AssignNotes moves the given owned notes into the portfolio with the given
+// ID. Each note gets its own result, so some notes may be moved while others
+// are rejected.
+func (ar *AccountsResource) AssignNotes(portfolioID int, notes []Note) ([]NoteAssignment, error) {
+	return ar.AssignNotesContext(context.Background(), portfolioID, notes)
+}
+
+func (ar *AccountsResource) AssignNotesContext(ctx context.Context, portfolioID int, notes []Note) ([]NoteAssignment, error) {
+	type noteRef struct {
+		NoteID  int64 `json:"noteId"`
+		OrderID int64 `json:"orderId"`
+		LoanID  int64 `json:"loanId"`
+	}
+	refs := make([]noteRef, len(notes))
+	for i, note := range notes {
+		refs[i] = noteRef{
+			NoteID:  note.ID.IntPart(),
+			OrderID: note.OrderID.IntPart(),
+			LoanID:  note.LoanID.IntPart(),
+		}
+	}
+
+	assignment := struct {
+		Notes []noteRef `json:"notes"`
+	}{
+		Notes: refs,
+	}
+	payload, err := json.Marshal(assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := ar.endpoint + fmt.Sprintf(portfolioNotesEndpoint, portfolioID)
+	if ar.client.dryRun {
+		ar.client.logDryRun("POST", endpoint, payload)
+		return simulateAssignment(portfolioID, notes)
+	}
+
+	req, err := ar.client.newRequest(ctx, "POST", endpoint, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := ar.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var results struct {
+		Assignments []NoteAssignment `json:"results"`
+	}
+	err = ar.client.processResponse(res, &results)
+
+	return results.Assignments, err
+}
+
 type OrderSubmission struct {
 	LoanID      int             `json:"loanId"`
 	Amount      decimal.Decimal `json:"requestedAmount"`
@@ -322,10 +585,10 @@ type OrderSubmission struct {
 }
 
 type OrderConfirmation struct {
-	LoanID          int             `json:"loanId"`
-	RequestedAmount decimal.Decimal `json:"requestedAmount"`
-	InvestedAmount  int             `json:"investedAmount"`
-	ExecutionStatus string          `json:"executionStatus"`
+	LoanID          int               `json:"loanId"`
+	RequestedAmount decimal.Decimal   `json:"requestedAmount"`
+	InvestedAmount  decimal.Decimal   `json:"investedAmount"`
+	ExecutionStatus ExecutionStatuses `json:"executionStatus"`
 }
 
 type OrderInstruct struct {
@@ -333,13 +596,19 @@ type OrderInstruct struct {
 	OrderConfirmations []OrderConfirmation `json:"orderConfirmations"`
 }
 
-func (ar *AccountsResource) SubmitOrder(accountID int, orders []OrderSubmission) (*OrderInstruct, error) {
+// SubmitOrder places orders on behalf of the investor the resource is bound
+// to.
+func (ar *AccountsResource) SubmitOrder(orders []OrderSubmission) (*OrderInstruct, error) {
+	return ar.SubmitOrderContext(context.Background(), orders)
+}
+
+func (ar *AccountsResource) SubmitOrderContext(ctx context.Context, orders []OrderSubmission) (*OrderInstruct, error) {
 	orderSubmission := struct {
 		Orders    []OrderSubmission `json:"orders"`
 		AccountID int               `json:"aid"`
 	}{
 		Orders:    orders,
-		AccountID: accountID,
+		AccountID: ar.investorID,
 	}
 
 	payload, err := json.Marshal(orderSubmission)
@@ -347,7 +616,12 @@ func (ar *AccountsResource) SubmitOrder(accountID int, orders []OrderSubmission)
 		return nil, err
 	}
 
-	req, err := ar.client.newRequest("POST", ar.endpoint+ordersEndpoint, bytes.NewBuffer(payload))
+	if ar.client.dryRun {
+		ar.client.logDryRun("POST", ar.endpoint+ordersEndpoint, payload)
+		return simulateOrder(ar.client.nextDryRunID(), orders)
+	}
+
+	req, err := ar.client.newRequest(ctx, "POST", ar.endpoint+ordersEndpoint, bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, err
 	}
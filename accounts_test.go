@@ -65,7 +65,7 @@ func TestSummary(t *testing.T) {
 func TestAddFunds(t *testing.T) {
 	fp := &FundsPayload{
 		Amount:            decimal.NewFromFloat(100),
-		TransferFrequency: "LOAD_NOW",
+		TransferFrequency: LoadNow,
 	}
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		defer req.Body.Close()
@@ -90,7 +90,7 @@ func TestAddFunds(t *testing.T) {
 
 	assert.Equal(t, 12345, deposit.InvestorID)
 	assert.Equal(t, decimal.NewFromFloat(100), deposit.Amount)
-	assert.Equal(t, "LOAD_NOW", deposit.Frequency)
+	assert.Equal(t, LoadNow, deposit.Frequency)
 
 	ti, err := time.Parse(timeFormat, "2015-01-22T00:00:00.000-0800")
 	require.NoError(t, err)
@@ -131,3 +131,201 @@ func TestWithdrawFunds(t *testing.T) {
 	assert.Equal(t, 12345, withdrawal.InvestorID)
 	assert.Equal(t, ti, withdrawal.EstimatedFundsTransferDate.Time)
 }
+
+func TestPendingFunds(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		pendingFundsAPI := fmt.Sprintf("/accounts/%d/funds/pending", TestAccountID)
+		assert.Equal(t, pendingFundsAPI, req.RequestURI)
+
+		err := respondWithFixture(w, "pending_funds.json")
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	ar := newClient(ts.URL, "Token", nil).Accounts(TestAccountID)
+	transfers, err := ar.PendingFunds()
+	require.NoError(t, err)
+	require.Len(t, transfers, 3)
+
+	assert.Equal(t, 30001, transfers[0].TransferID)
+	assert.Equal(t, 30002, transfers[1].TransferID)
+	assert.Equal(t, 30003, transfers[2].TransferID)
+	assert.Equal(t, TransferScheduled, transfers[2].Status)
+	assert.Equal(t, LoadMonthly, transfers[2].Frequency)
+
+	transfers, err = ar.PendingFunds(ByOperation(TransferDeposit), ByCancellable(true))
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	assert.Equal(t, 30003, transfers[0].TransferID)
+
+	transfers, err = ar.PendingFunds(ByStatus(TransferPending))
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	assert.Equal(t, TransferWithdrawal, transfers[1].Operation)
+}
+
+func TestCancelPendingFunds(t *testing.T) {
+	var cancelled []int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer req.Body.Close()
+
+		switch req.RequestURI {
+		case fmt.Sprintf("/accounts/%d/funds/pending", TestAccountID):
+			err := respondWithFixture(w, "pending_funds.json")
+			require.NoError(t, err)
+		case fmt.Sprintf("/accounts/%d/funds/cancel", TestAccountID):
+			var body struct {
+				TransferIDs []int `json:"transferIds"`
+			}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			cancelled = append(cancelled, body.TransferIDs...)
+
+			fmt.Fprintf(w, `{"investorId": %d, "cancellationResults": [{"transferId": 30002, "status": "SUCCESS"}]}`, TestAccountID)
+		default:
+			t.Errorf("unexpected request %s", req.RequestURI)
+		}
+	}))
+	defer ts.Close()
+
+	ar := newClient(ts.URL, "Token", nil).Accounts(TestAccountID)
+	cr, err := ar.CancelPendingFunds(ByOperation(TransferWithdrawal))
+	require.NoError(t, err)
+	require.Len(t, cr.Cancellations, 1)
+	assert.Equal(t, []int{30002}, cancelled)
+
+	cr, err = ar.CancelPendingFunds(ByStatus(TransferPending), ByOperation(TransferDeposit))
+	require.NoError(t, err)
+	assert.Empty(t, cr.Cancellations)
+	assert.Len(t, cancelled, 1)
+
+	// The caller's filters are left untouched, even with spare capacity.
+	filters := make([]TransferFilter, 1, 2)
+	filters[0] = ByOperation(TransferDeposit)
+	_, err = ar.CancelPendingFunds(filters...)
+	require.NoError(t, err)
+	assert.Nil(t, filters[:2][1])
+}
+
+func TestDetailedNotes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		detailedNotesAPI := fmt.Sprintf("/accounts/%d/detailednotes", TestAccountID)
+		assert.Equal(t, detailedNotesAPI, req.RequestURI)
+
+		err := respondWithFixture(w, "detailed_notes.json")
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	ar := newClient(ts.URL, "Token", nil).Accounts(TestAccountID)
+	notes, err := ar.DetailedNotes()
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+
+	current := notes[0]
+	assert.Equal(t, "22222", current.ID.String())
+	assert.Equal(t, "11111", current.LoanID.String())
+	assert.Equal(t, 44444, current.PortfolioID)
+	assert.Equal(t, "Grade A", current.PortfolioName)
+	assert.Equal(t, "2.48", current.PrincipalReceived.String())
+	assert.Equal(t, "0.64", current.InterestReceived.String())
+	assert.Equal(t, "0.05", current.AccruedInterest.String())
+	assert.Equal(t, "UP", current.CreditTrend)
+	assert.True(t, current.CanBeTraded)
+
+	ti, err := time.Parse(timeFormat, "2015-05-15T00:00:00.000-0700")
+	require.NoError(t, err)
+	require.NotNil(t, current.NextPaymentDate)
+	assert.Equal(t, ti, current.NextPaymentDate.Time)
+
+	inFunding := notes[1]
+	assert.Equal(t, LoanInFunding, inFunding.LoanStatus)
+	assert.True(t, inFunding.CanUseTruncatedSearch)
+	assert.Nil(t, inFunding.IssueDate)
+	assert.Nil(t, inFunding.LoanStatusDate)
+	assert.Nil(t, inFunding.LastPaymentDate)
+	assert.Nil(t, inFunding.NextPaymentDate)
+}
+
+func TestUpdatePortfolio(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer req.Body.Close()
+		portfolioAPI := fmt.Sprintf("/accounts/%d/portfolios/44444", TestAccountID)
+		assert.Equal(t, portfolioAPI, req.RequestURI)
+		assert.Equal(t, "PUT", req.Method)
+
+		var body Portfolio
+		err := json.NewDecoder(req.Body).Decode(&body)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Grade A and B", body.Name)
+		assert.Equal(t, "Low risk notes", body.Description)
+
+		err = respondWithFixture(w, "update_portfolio.json")
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	ar := newClient(ts.URL, "Token", nil).Accounts(TestAccountID)
+	portfolio, err := ar.UpdatePortfolio(44444, "Grade A and B", "Low risk notes")
+	require.NoError(t, err)
+
+	assert.Equal(t, 44444, portfolio.ID)
+	assert.Equal(t, "Grade A and B", portfolio.Name)
+	assert.Equal(t, "Low risk notes", portfolio.Description)
+}
+
+func TestDeletePortfolio(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		portfolioAPI := fmt.Sprintf("/accounts/%d/portfolios/44444", TestAccountID)
+		assert.Equal(t, portfolioAPI, req.RequestURI)
+		assert.Equal(t, "DELETE", req.Method)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	ar := newClient(ts.URL, "Token", nil).Accounts(TestAccountID)
+	err := ar.DeletePortfolio(44444)
+	require.NoError(t, err)
+}
+
+func TestAssignNotes(t *testing.T) {
+	notes := []Note{
+		{ID: decimal.New(22222, 0), OrderID: decimal.New(33333, 0), LoanID: decimal.New(11111, 0)},
+		{ID: decimal.New(22223, 0), OrderID: decimal.New(33333, 0), LoanID: decimal.New(11112, 0)},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer req.Body.Close()
+		portfolioNotesAPI := fmt.Sprintf("/accounts/%d/portfolios/44444/notes", TestAccountID)
+		assert.Equal(t, portfolioNotesAPI, req.RequestURI)
+
+		var body struct {
+			Notes []struct {
+				NoteID  int `json:"noteId"`
+				OrderID int `json:"orderId"`
+				LoanID  int `json:"loanId"`
+			} `json:"notes"`
+		}
+		err := json.NewDecoder(req.Body).Decode(&body)
+		require.NoError(t, err)
+
+		require.Len(t, body.Notes, 2)
+		assert.Equal(t, 22223, body.Notes[1].NoteID)
+		assert.Equal(t, 33333, body.Notes[1].OrderID)
+		assert.Equal(t, 11112, body.Notes[1].LoanID)
+
+		err = respondWithFixture(w, "assign_notes.json")
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	ar := newClient(ts.URL, "Token", nil).Accounts(TestAccountID)
+	results, err := ar.AssignNotes(44444, notes)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "22222", results[0].NoteID.String())
+	assert.Equal(t, "SUCCESS", results[0].Status)
+	assert.Equal(t, "NOTE_NOT_OWNED", results[1].Status)
+	assert.Equal(t, "Note is not owned by the investor", results[1].Message)
+}
@@ -0,0 +1,149 @@
+package analytics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	hundred       = decimal.New(100, 0)
+	monthsPerYear = decimal.New(12, 0)
+)
+
+// Payment is one monthly payment of a schedule. Fee is the service fee on
+// the payment and Net what the investor keeps.
+type Payment struct {
+	Number    int
+	Date      time.Time
+	Payment   decimal.Decimal
+	Principal decimal.Decimal
+	Interest  decimal.Decimal
+	Fee       decimal.Decimal
+	Net       decimal.Decimal
+	Balance   decimal.Decimal
+}
+
+// Schedule is the amortization schedule of a loan or note.
+type Schedule []Payment
+
+// Total sums the payments of the schedule. Its Balance is what is left
+// after the last payment.
+func (s Schedule) Total() Payment {
+	t := Payment{
+		Payment:   decimal.Zero,
+		Principal: decimal.Zero,
+		Interest:  decimal.Zero,
+		Fee:       decimal.Zero,
+		Net:       decimal.Zero,
+		Balance:   decimal.Zero,
+	}
+	for _, p := range s {
+		t.Payment = t.Payment.Add(p.Payment)
+		t.Principal = t.Principal.Add(p.Principal)
+		t.Interest = t.Interest.Add(p.Interest)
+		t.Fee = t.Fee.Add(p.Fee)
+		t.Net = t.Net.Add(p.Net)
+		t.Balance = p.Balance
+		t.Number = p.Number
+		t.Date = p.Date
+	}
+
+	return t
+}
+
+// Amortize returns the schedule of principal repaid over term months by a
+// fixed installment, the first due a month after start. Rates are annual
+// percentages, as the API reports them. A zero installment is computed from
+// the rate and term. Amounts are rounded to the cent and the last payment
+// clears whatever balance is left.
+func Amortize(principal, interestRate decimal.Decimal, term int, installment, serviceFeeRate decimal.Decimal, start time.Time) (Schedule, error) {
+	switch {
+	case principal.Sign() <= 0:
+		return nil, fmt.Errorf("%w: principal must be positive, got %s", lendingclub.ErrValidation, principal)
+	case term <= 0:
+		return nil, fmt.Errorf("%w: term must be positive, got %d", lendingclub.ErrValidation, term)
+	case interestRate.Sign() < 0 || serviceFeeRate.Sign() < 0:
+		return nil, fmt.Errorf("%w: rates must not be negative", lendingclub.ErrValidation)
+	}
+
+	rate := monthlyRate(interestRate)
+	if installment.Sign() <= 0 {
+		installment = annuity(principal, rate, term)
+	}
+	feeRate := serviceFeeRate.Div(hundred)
+
+	schedule := make(Schedule, 0, term)
+	balance := principal
+	for n := 1; n <= term && balance.Sign() > 0; n++ {
+		interest := cents(balance.Mul(rate))
+		paid := installment
+		principalPaid := paid.Sub(interest)
+		if n == term || balance.LessThan(principalPaid) {
+			principalPaid = balance
+			paid = principalPaid.Add(interest)
+		}
+		balance = balance.Sub(principalPaid)
+		fee := cents(paid.Mul(feeRate))
+
+		schedule = append(schedule, Payment{
+			Number:    n,
+			Date:      start.AddDate(0, n, 0),
+			Payment:   paid,
+			Principal: principalPaid,
+			Interest:  interest,
+			Fee:       fee,
+			Net:       paid.Sub(fee),
+			Balance:   balance,
+		})
+	}
+
+	return schedule, nil
+}
+
+// LoanSchedule returns the schedule of a listed loan issued at start.
+func LoanSchedule(loan lendingclub.Loan, start time.Time) (Schedule, error) {
+	return Amortize(loan.LoanAmount, loan.InterestRate, loan.Term, loan.Installment, loan.ServiceFeeRate, start)
+}
+
+// NoteSchedule returns the schedule of a note from its issue date, net of
+// DefaultServiceFee. Notes not issued yet are scheduled from their order
+// date.
+func NoteSchedule(note lendingclub.Note) (Schedule, error) {
+	start := note.IssueDate.Time
+	if start.IsZero() {
+		start = note.OrderDate.Time
+	}
+	if start.IsZero() {
+		return nil, fmt.Errorf("%w: note has neither an issue nor an order date", lendingclub.ErrValidation)
+	}
+
+	return Amortize(note.Amount, note.InterestRate, note.LoanLength, decimal.Zero, DefaultServiceFee.Mul(hundred), start)
+}
+
+// monthlyRate converts an annual percentage to a monthly fraction.
+func monthlyRate(annual decimal.Decimal) decimal.Decimal {
+	return annual.Div(hundred).Div(monthsPerYear)
+}
+
+// annuity returns the installment, rounded to the cent, that repays
+// principal over term months at the monthly rate.
+func annuity(principal, rate decimal.Decimal, term int) decimal.Decimal {
+	if rate.Sign() == 0 {
+		return cents(principal.Div(decimal.New(int64(term), 0)))
+	}
+
+	growth := decimal.New(1, 0)
+	base := rate.Add(decimal.New(1, 0))
+	for i := 0; i < term; i++ {
+		growth = growth.Mul(base).Round(20)
+	}
+
+	return cents(principal.Mul(rate).Mul(growth).Div(growth.Sub(decimal.New(1, 0))))
+}
+
+func cents(d decimal.Decimal) decimal.Decimal {
+	return d.Round(2)
+}
@@ -0,0 +1,114 @@
+package analytics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmortize(t *testing.T) {
+	s, err := Amortize(decimal.New(10000, 0), decimal.New(726, -2), 36, decimal.Zero, decimal.New(1, 0), date(2015, 6, 5))
+	require.NoError(t, err)
+	require.Len(t, s, 36)
+
+	first := s[0]
+	assert.Equal(t, date(2015, 7, 5), first.Date)
+	assert.Equal(t, "309.96", first.Payment.String())
+	assert.Equal(t, "60.5", first.Interest.String())
+	assert.Equal(t, "249.46", first.Principal.String())
+	assert.Equal(t, "3.1", first.Fee.String())
+	assert.Equal(t, "306.86", first.Net.String())
+	assert.Equal(t, "9750.54", first.Balance.String())
+
+	total := s.Total()
+	assert.Equal(t, "10000", total.Principal.String())
+	assert.Equal(t, "0", total.Balance.String())
+	assert.Equal(t, total.Payment.String(), total.Principal.Add(total.Interest).String())
+	assert.Equal(t, total.Net.String(), total.Payment.Sub(total.Fee).String())
+
+	_, err = Amortize(decimal.New(10000, 0), decimal.New(726, -2), 0, decimal.Zero, decimal.Zero, date(2015, 6, 5))
+	assert.True(t, errors.Is(err, lendingclub.ErrValidation))
+}
+
+func TestLoanSchedule(t *testing.T) {
+	loan := lendingclub.Loan{
+		LoanAmount:     decimal.New(10000, 0),
+		InterestRate:   decimal.New(726, -2),
+		Term:           36,
+		Installment:    decimal.New(31001, -2),
+		ServiceFeeRate: decimal.New(1, 0),
+	}
+
+	s, err := LoanSchedule(loan, date(2015, 6, 5))
+	require.NoError(t, err)
+	require.Len(t, s, 36)
+	assert.Equal(t, "310.01", s[0].Payment.String())
+	assert.Equal(t, "10000", s.Total().Principal.String())
+}
+
+func TestNoteSchedule(t *testing.T) {
+	note := lendingclub.Note{
+		Amount:       decimal.New(25, 0),
+		InterestRate: decimal.New(1757, -2),
+		LoanLength:   60,
+		IssueDate:    lendingclub.Time{Time: date(2015, 1, 15)},
+	}
+
+	s, err := NoteSchedule(note)
+	require.NoError(t, err)
+	require.Len(t, s, 60)
+	assert.Equal(t, date(2015, 2, 15), s[0].Date)
+	assert.Equal(t, "25", s.Total().Principal.String())
+	assert.Equal(t, "0", s[59].Balance.String())
+
+	// A note in funding is scheduled from its order date.
+	note.IssueDate = lendingclub.Time{}
+	note.OrderDate = lendingclub.Time{Time: date(2015, 3, 2)}
+	s, err = NoteSchedule(note)
+	require.NoError(t, err)
+	assert.Equal(t, date(2015, 4, 2), s[0].Date)
+
+	note.OrderDate = lendingclub.Time{}
+	_, err = NoteSchedule(note)
+	assert.True(t, errors.Is(err, lendingclub.ErrValidation))
+}
+
+func TestProject(t *testing.T) {
+	loan := lendingclub.Loan{
+		InterestRate:        decimal.New(1825, -2),
+		Term:                60,
+		ServiceFeeRate:      decimal.New(1, 0),
+		ExpectedDefaultRate: decimal.New(91, -1),
+	}
+	investments := []Investment{
+		LoanInvestment(loan, decimal.New(100, 0)),
+		NoteInvestment(lendingclub.Note{Amount: decimal.New(50, 0), InterestRate: decimal.New(726, -2), LoanLength: 36}, decimal.New(5, 0)),
+	}
+	assert.Equal(t, "5", investments[1].DefaultRate.String())
+
+	p := &Projector{PrepaymentRate: decimal.New(10, 0)}
+	months := p.Project(investments, date(2015, 6, 5))
+	require.Len(t, months, 60)
+	assert.Equal(t, date(2015, 7, 5), months[0].Date)
+	assert.Equal(t, "0", months[59].Balance.String())
+
+	principal, defaulted := decimal.Zero, decimal.Zero
+	for _, m := range months {
+		principal = principal.Add(m.Principal).Add(m.Prepaid)
+		defaulted = defaulted.Add(m.Defaulted)
+		assert.Equal(t, m.Net.String(), m.Principal.Add(m.Prepaid).Add(m.Interest).Sub(m.Fee).String())
+	}
+	assert.Equal(t, "150", principal.Add(defaulted).String())
+	assert.True(t, defaulted.Sign() > 0)
+
+	// Overriding the default rate applies to every investment.
+	none := decimal.Zero
+	months = (&Projector{DefaultRate: &none}).Project(investments, date(2015, 6, 5))
+	for _, m := range months {
+		assert.Equal(t, "0", m.Defaulted.String())
+	}
+}
@@ -0,0 +1,134 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+)
+
+// Investment is an amount lent at a rate and repaid monthly, such as a note
+// or an order for a listed loan. Rates are annual percentages.
+type Investment struct {
+	Amount         decimal.Decimal
+	InterestRate   decimal.Decimal
+	Term           int
+	ServiceFeeRate decimal.Decimal
+	DefaultRate    decimal.Decimal
+}
+
+// LoanInvestment is amount invested in loan, defaulting at the loan's
+// ExpectedDefaultRate.
+func LoanInvestment(loan lendingclub.Loan, amount decimal.Decimal) Investment {
+	return Investment{
+		Amount:         amount,
+		InterestRate:   loan.InterestRate,
+		Term:           loan.Term,
+		ServiceFeeRate: loan.ServiceFeeRate,
+		DefaultRate:    loan.ExpectedDefaultRate,
+	}
+}
+
+// NoteInvestment is an owned note defaulting at defaultRate, an annual
+// percentage. Notes carry no expected default rate, so it must be given,
+// such as the ExpectedDefaultRate of the loan when it was listed.
+func NoteInvestment(note lendingclub.Note, defaultRate decimal.Decimal) Investment {
+	return Investment{
+		Amount:         note.Amount,
+		InterestRate:   note.InterestRate,
+		Term:           note.LoanLength,
+		ServiceFeeRate: DefaultServiceFee.Mul(hundred),
+		DefaultRate:    defaultRate,
+	}
+}
+
+// Projector projects the monthly cash flows of a set of investments.
+type Projector struct {
+	// PrepaymentRate is the annual percentage of the outstanding balance
+	// repaid early. Zero means no prepayments.
+	PrepaymentRate decimal.Decimal
+
+	// DefaultRate, if set, replaces the annual default rate of every
+	// investment.
+	DefaultRate *decimal.Decimal
+}
+
+// ProjectedMonth is the cash flow of all investments in one month. Defaulted
+// is principal written off; Net is what the investor receives after fees.
+type ProjectedMonth struct {
+	Month     int
+	Date      time.Time
+	Principal decimal.Decimal
+	Prepaid   decimal.Decimal
+	Interest  decimal.Decimal
+	Fee       decimal.Decimal
+	Defaulted decimal.Decimal
+	Net       decimal.Decimal
+	Balance   decimal.Decimal
+}
+
+// Project returns the expected cash flows of investments month by month from
+// start until every investment is repaid or written off.
+//
+// Each month a share of the balance defaults, the survivors pay the
+// installment that amortizes their balance over the remaining term, and a
+// share of what is left is prepaid. Amounts are rounded to the cent, so
+// Principal, Prepaid and Defaulted add up exactly to what was invested.
+func (p *Projector) Project(investments []Investment, start time.Time) []ProjectedMonth {
+	var months []ProjectedMonth
+	prepay := monthlyRate(p.PrepaymentRate)
+
+	for _, inv := range investments {
+		defaultRate := inv.DefaultRate
+		if p.DefaultRate != nil {
+			defaultRate = *p.DefaultRate
+		}
+		rate := monthlyRate(inv.InterestRate)
+		defaults := monthlyRate(defaultRate)
+		feeRate := inv.ServiceFeeRate.Div(hundred)
+
+		balance := inv.Amount
+		for n := 1; n <= inv.Term && balance.Sign() > 0; n++ {
+			if len(months) < n {
+				months = append(months, newProjectedMonth(n, start.AddDate(0, n, 0)))
+			}
+			m := &months[n-1]
+
+			defaulted := cents(balance.Mul(defaults))
+			balance = balance.Sub(defaulted)
+
+			interest := cents(balance.Mul(rate))
+			principal := annuity(balance, rate, inv.Term-n+1).Sub(interest)
+			if n == inv.Term || balance.LessThan(principal) {
+				principal = balance
+			}
+			prepaid := cents(balance.Sub(principal).Mul(prepay))
+			balance = balance.Sub(principal).Sub(prepaid)
+			fee := cents(interest.Add(principal).Add(prepaid).Mul(feeRate))
+
+			m.Principal = m.Principal.Add(principal)
+			m.Prepaid = m.Prepaid.Add(prepaid)
+			m.Interest = m.Interest.Add(interest)
+			m.Fee = m.Fee.Add(fee)
+			m.Defaulted = m.Defaulted.Add(defaulted)
+			m.Net = m.Net.Add(interest.Add(principal).Add(prepaid).Sub(fee))
+			m.Balance = m.Balance.Add(balance)
+		}
+	}
+
+	return months
+}
+
+func newProjectedMonth(n int, date time.Time) ProjectedMonth {
+	return ProjectedMonth{
+		Month:     n,
+		Date:      date,
+		Principal: decimal.Zero,
+		Prepaid:   decimal.Zero,
+		Interest:  decimal.Zero,
+		Fee:       decimal.Zero,
+		Defaulted: decimal.Zero,
+		Net:       decimal.Zero,
+		Balance:   decimal.Zero,
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Package analytics measures the performance of Lending Club notes.
+
+An Analyzer computes, from the notes returned by DetailedNotes, the Net
+Annualized Return the way Lending Club defines it, an adjusted NAR that marks
+late notes down by their expected loss, and the XIRR of the notes' cash
+flows. Results are given for the whole account and broken down by grade,
+term, vintage and portfolio.
+
+The API only reports totals per note, not each monthly payment, so
+outstanding principal is assumed to have declined evenly over a note's life
+and payments to have arrived on the last payment date. Results are close to,
+but not exactly, those on the Lending Club site.
+
+Amortize, LoanSchedule and NoteSchedule split each monthly payment into
+principal, interest and service fee, and a Projector forecasts the monthly
+cash flows of many investments under prepayment and default assumptions.
+All amounts are decimals rounded to the cent.
+*/
+package analytics
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultServiceFee is Lending Club's fee on every payment received.
+var DefaultServiceFee = decimal.New(1, -2)
+
+// DefaultLossRates are the shares of outstanding principal Lending Club
+// historically expects to lose on notes in each status.
+var DefaultLossRates = map[lendingclub.LoanStatus]decimal.Decimal{
+	lendingclub.LoanInGracePeriod: decimal.New(24, -2),
+	lendingclub.LoanLate16To30:    decimal.New(59, -2),
+	lendingclub.LoanLate31To120:   decimal.New(77, -2),
+	lendingclub.LoanDefault:       decimal.New(90, -2),
+}
+
+// daysPerMonth is the average length of a month.
+const daysPerMonth = 365.25 / 12
+
+// Analyzer computes returns from notes.
+type Analyzer struct {
+	// ServiceFee is the share of each payment kept by Lending Club. It
+	// defaults to DefaultServiceFee.
+	ServiceFee decimal.Decimal
+
+	// LossRates marks notes down by status in the adjusted NAR. It defaults
+	// to DefaultLossRates.
+	LossRates map[lendingclub.LoanStatus]decimal.Decimal
+}
+
+// Returns is the performance of a group of notes. Notes still in funding
+// or issuing are counted but do not contribute to the rates.
+type Returns struct {
+	Notes       int
+	Invested    decimal.Decimal
+	Outstanding decimal.Decimal
+	Interest    decimal.Decimal
+	LateFees    decimal.Decimal
+	ServiceFees decimal.Decimal
+	ChargeOffs  decimal.Decimal
+
+	// ExpectedLoss is the outstanding principal of late notes weighted by
+	// their loss rates.
+	ExpectedLoss decimal.Decimal
+
+	NAR         float64
+	AdjustedNAR float64
+
+	// XIRR is the internal rate of return of the notes' cash flows, valuing
+	// outstanding principal at par. It is NaN when there is no rate.
+	XIRR float64
+
+	earned         decimal.Decimal
+	principalMonth decimal.Decimal
+	flows          []CashFlow
+}
+
+// Report is the performance of an account's notes as of a date. Grades are
+// keyed by letter, Terms by months, Vintages by issue year and Portfolios by
+// name, with "" for notes in no portfolio.
+type Report struct {
+	AsOf       time.Time
+	Total      *Returns
+	Grades     map[string]*Returns
+	Terms      map[string]*Returns
+	Vintages   map[string]*Returns
+	Portfolios map[string]*Returns
+}
+
+// Account fetches the detailed notes of the account and analyzes them as of
+// now.
+func (a *Analyzer) Account(ar *lendingclub.AccountsResource) (*Report, error) {
+	return a.AccountContext(context.Background(), ar)
+}
+
+func (a *Analyzer) AccountContext(ctx context.Context, ar *lendingclub.AccountsResource) (*Report, error) {
+	notes, err := ar.DetailedNotesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.Analyze(notes, time.Now()), nil
+}
+
+// Analyze computes the returns of notes as of asOf.
+func (a *Analyzer) Analyze(notes []lendingclub.DetailedNote, asOf time.Time) *Report {
+	r := &Report{
+		AsOf:       asOf,
+		Total:      newReturns(),
+		Grades:     make(map[string]*Returns),
+		Terms:      make(map[string]*Returns),
+		Vintages:   make(map[string]*Returns),
+		Portfolios: make(map[string]*Returns),
+	}
+
+	for _, n := range notes {
+		vintage := ""
+		if n.IssueDate != nil {
+			vintage = strconv.Itoa(n.IssueDate.Year())
+		}
+
+		groups := []*Returns{
+			r.Total,
+			group(r.Grades, n.Grade.Grade().String()),
+			group(r.Terms, strconv.Itoa(n.LoanLength)),
+			group(r.Vintages, vintage),
+			group(r.Portfolios, n.PortfolioName),
+		}
+		for _, g := range groups {
+			a.add(g, n, asOf)
+		}
+	}
+
+	r.Total.finish()
+	for _, m := range []map[string]*Returns{r.Grades, r.Terms, r.Vintages, r.Portfolios} {
+		for _, g := range m {
+			g.finish()
+		}
+	}
+
+	return r
+}
+
+func group(m map[string]*Returns, key string) *Returns {
+	g, ok := m[key]
+	if !ok {
+		g = newReturns()
+		m[key] = g
+	}
+
+	return g
+}
+
+func newReturns() *Returns {
+	return &Returns{
+		Invested:       decimal.Zero,
+		Outstanding:    decimal.Zero,
+		Interest:       decimal.Zero,
+		LateFees:       decimal.Zero,
+		ServiceFees:    decimal.Zero,
+		ChargeOffs:     decimal.Zero,
+		ExpectedLoss:   decimal.Zero,
+		earned:         decimal.Zero,
+		principalMonth: decimal.Zero,
+	}
+}
+
+func (a *Analyzer) serviceFee() decimal.Decimal {
+	if a.ServiceFee.Sign() == 0 {
+		return DefaultServiceFee
+	}
+
+	return a.ServiceFee
+}
+
+func (a *Analyzer) lossRate(status lendingclub.LoanStatus) decimal.Decimal {
+	rates := a.LossRates
+	if rates == nil {
+		rates = DefaultLossRates
+	}
+	if rate, ok := rates[status]; ok {
+		return rate
+	}
+
+	return decimal.Zero
+}
+
+// add accumulates note n into g. Each note contributes the interest and late
+// fees it earned net of service fees and charge-offs, and the principal it
+// had outstanding times the months it was outstanding.
+func (a *Analyzer) add(g *Returns, n lendingclub.DetailedNote, asOf time.Time) {
+	g.Notes++
+	g.Invested = g.Invested.Add(n.Amount)
+	if n.IssueDate == nil {
+		return
+	}
+
+	end := asOf
+	chargedOff := n.LoanStatus == lendingclub.LoanChargedOff
+	if (chargedOff || n.LoanStatus == lendingclub.LoanFullyPaid) && n.LoanStatusDate != nil {
+		end = n.LoanStatusDate.Time
+	}
+	months := end.Sub(n.IssueDate.Time).Hours() / 24 / daysPerMonth
+	if months <= 0 {
+		return
+	}
+
+	fees := n.PaymentsReceived.Mul(a.serviceFee())
+	earned := n.InterestReceived.Add(n.LateFeesReceived).Sub(fees)
+	if chargedOff {
+		g.ChargeOffs = g.ChargeOffs.Add(n.PrincipalPending)
+		earned = earned.Sub(n.PrincipalPending)
+	} else {
+		g.Outstanding = g.Outstanding.Add(n.PrincipalPending)
+		g.ExpectedLoss = g.ExpectedLoss.Add(n.PrincipalPending.Mul(a.lossRate(n.LoanStatus)))
+	}
+
+	// Principal is assumed to have been repaid evenly, so the average
+	// outstanding is halfway between the note amount and what is left.
+	average := n.Amount.Add(n.Amount.Sub(n.PrincipalReceived)).Div(decimal.New(2, 0))
+
+	g.Interest = g.Interest.Add(n.InterestReceived)
+	g.LateFees = g.LateFees.Add(n.LateFeesReceived)
+	g.ServiceFees = g.ServiceFees.Add(fees)
+	g.earned = g.earned.Add(earned)
+	g.principalMonth = g.principalMonth.Add(average.Mul(decimal.NewFromFloat(months)))
+
+	g.flows = append(g.flows, CashFlow{Date: n.OrderDate.Time, Amount: n.Amount.Neg()})
+	if n.PaymentsReceived.Sign() > 0 {
+		paid := end
+		if n.LastPaymentDate != nil {
+			paid = n.LastPaymentDate.Time
+		}
+		g.flows = append(g.flows, CashFlow{Date: paid, Amount: n.PaymentsReceived.Sub(fees)})
+	}
+	if !chargedOff && n.PrincipalPending.Sign() > 0 {
+		g.flows = append(g.flows, CashFlow{Date: asOf, Amount: n.PrincipalPending})
+	}
+}
+
+func (g *Returns) finish() {
+	g.NAR = annualize(g.earned, g.principalMonth)
+	g.AdjustedNAR = annualize(g.earned.Sub(g.ExpectedLoss), g.principalMonth)
+
+	rate, err := XIRR(g.flows)
+	if err != nil {
+		rate = math.NaN()
+	}
+	g.XIRR = rate
+}
+
+// annualize compounds the monthly return earned / principalMonth over a
+// year, as Lending Club does for NAR.
+func annualize(earned, principalMonth decimal.Decimal) float64 {
+	if principalMonth.Sign() <= 0 {
+		return 0
+	}
+
+	monthly, _ := earned.Div(principalMonth).Float64()
+	if monthly <= -1 {
+		return -1
+	}
+
+	return math.Pow(1+monthly, 12) - 1
+}
@@ -0,0 +1,117 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func lcTime(t time.Time) *lendingclub.Time {
+	return &lendingclub.Time{Time: t}
+}
+
+func testNotes() []lendingclub.DetailedNote {
+	issued := lcTime(date(2019, 1, 1))
+	return []lendingclub.DetailedNote{
+		{
+			Amount: decimal.New(1000, 0), LoanLength: 36, Grade: "A5", PortfolioName: "Safe",
+			LoanStatus: lendingclub.LoanCurrent, OrderDate: *issued, IssueDate: issued,
+			PaymentsReceived: decimal.New(120, 0), InterestReceived: decimal.New(120, 0),
+			PrincipalPending: decimal.New(1000, 0), LastPaymentDate: lcTime(date(2020, 1, 1)),
+		},
+		{
+			Amount: decimal.New(1000, 0), LoanLength: 60, Grade: "D2",
+			LoanStatus: lendingclub.LoanLate31To120, OrderDate: *issued, IssueDate: issued,
+			PaymentsReceived: decimal.New(100, 0), InterestReceived: decimal.New(100, 0),
+			PrincipalPending: decimal.New(1000, 0), LastPaymentDate: lcTime(date(2019, 9, 1)),
+		},
+		{
+			Amount: decimal.New(500, 0), LoanLength: 60, Grade: "D4",
+			LoanStatus: lendingclub.LoanChargedOff, OrderDate: *issued, IssueDate: issued,
+			PaymentsReceived: decimal.New(150, 0), InterestReceived: decimal.New(50, 0),
+			PrincipalReceived: decimal.New(100, 0), PrincipalPending: decimal.New(400, 0),
+			LoanStatusDate: lcTime(date(2019, 7, 1)), LastPaymentDate: lcTime(date(2019, 4, 1)),
+		},
+		{
+			Amount: decimal.New(25, 0), LoanLength: 36, Grade: "B1",
+			LoanStatus: lendingclub.LoanInFunding, OrderDate: lendingclub.Time{Time: date(2019, 12, 30)},
+		},
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	a := &Analyzer{}
+	r := a.Analyze(testNotes(), date(2020, 1, 1))
+
+	assert.Equal(t, 4, r.Total.Notes)
+	assert.Equal(t, "2525", r.Total.Invested.String())
+	assert.Equal(t, "400", r.Total.ChargeOffs.String())
+	assert.Equal(t, "2000", r.Total.Outstanding.String())
+	assert.Equal(t, "770", r.Total.ExpectedLoss.String())
+
+	// 118.80 earned on 1000 outstanding for a year.
+	grade := r.Grades["A"]
+	require.NotNil(t, grade)
+	assert.InDelta(t, 0.1257, grade.NAR, 0.0005)
+	assert.Equal(t, grade.NAR, grade.AdjustedNAR)
+	assert.InDelta(t, 0.1188, grade.XIRR, 0.0005)
+	assert.Equal(t, "1.2", grade.ServiceFees.String())
+
+	d := r.Grades["D"]
+	require.NotNil(t, d)
+	assert.Equal(t, 2, d.Notes)
+	assert.True(t, d.NAR < 0)
+	assert.True(t, d.AdjustedNAR < d.NAR)
+	assert.True(t, r.Total.AdjustedNAR < r.Total.NAR)
+
+	assert.Equal(t, 2, r.Terms["60"].Notes)
+	assert.Equal(t, 3, r.Vintages["2019"].Notes)
+	assert.Equal(t, 1, r.Vintages[""].Notes)
+	assert.Equal(t, 1, r.Portfolios["Safe"].Notes)
+	assert.Equal(t, 3, r.Portfolios[""].Notes)
+
+	// A note still in funding has no return yet.
+	b := r.Grades["B"]
+	assert.Equal(t, 0.0, b.NAR)
+	assert.True(t, math.IsNaN(b.XIRR))
+}
+
+func TestAnalyzeLossRates(t *testing.T) {
+	a := &Analyzer{LossRates: map[lendingclub.LoanStatus]decimal.Decimal{}}
+	r := a.Analyze(testNotes(), date(2020, 1, 1))
+
+	assert.Equal(t, "0", r.Total.ExpectedLoss.String())
+	assert.Equal(t, r.Total.NAR, r.Total.AdjustedNAR)
+}
+
+func TestXIRR(t *testing.T) {
+	rate, err := XIRR([]CashFlow{
+		{Date: date(2021, 1, 1), Amount: decimal.New(-1000, 0)},
+		{Date: date(2022, 1, 1), Amount: decimal.New(1100, 0)},
+	})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.1, rate, 1e-6)
+
+	rate, err = XIRR([]CashFlow{
+		{Date: date(2021, 7, 1), Amount: decimal.New(600, 0)},
+		{Date: date(2021, 1, 1), Amount: decimal.New(-1000, 0)},
+		{Date: date(2022, 1, 1), Amount: decimal.New(300, 0)},
+	})
+	require.NoError(t, err)
+	assert.InDelta(t, -0.1458, rate, 0.0005)
+
+	_, err = XIRR([]CashFlow{
+		{Date: date(2021, 1, 1), Amount: decimal.New(100, 0)},
+		{Date: date(2022, 1, 1), Amount: decimal.New(100, 0)},
+	})
+	assert.Equal(t, ErrNoRate, err)
+}
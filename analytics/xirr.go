@@ -0,0 +1,81 @@
+package analytics
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrNoRate is returned by XIRR when the cash flows have no internal rate of
+// return, such as when they are all of the same sign.
+var ErrNoRate = errors.New("analytics: cash flows have no internal rate of return")
+
+// CashFlow is an amount paid (negative) or received (positive) on a date.
+type CashFlow struct {
+	Date   time.Time
+	Amount decimal.Decimal
+}
+
+const (
+	xirrMinRate    = -0.999999
+	xirrMaxRate    = 1e6
+	xirrTolerance  = 1e-10
+	xirrIterations = 200
+)
+
+// XIRR returns the annual rate at which the net present value of flows is
+// zero, counting time in 365-day years from the earliest flow.
+func XIRR(flows []CashFlow) (float64, error) {
+	if len(flows) < 2 {
+		return 0, ErrNoRate
+	}
+
+	sorted := append([]CashFlow(nil), flows...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	start := sorted[0].Date
+	years := make([]float64, len(sorted))
+	amounts := make([]float64, len(sorted))
+	for i, cf := range sorted {
+		years[i] = cf.Date.Sub(start).Hours() / 24 / 365
+		amounts[i], _ = cf.Amount.Float64()
+	}
+
+	npv := func(rate float64) float64 {
+		var v float64
+		for i, a := range amounts {
+			v += a / math.Pow(1+rate, years[i])
+		}
+		return v
+	}
+
+	lo, hi := xirrMinRate, 1.0
+	flo, fhi := npv(lo), npv(hi)
+	for fhi*flo > 0 && hi < xirrMaxRate {
+		hi *= 10
+		fhi = npv(hi)
+	}
+	if flo*fhi > 0 || math.IsNaN(flo) || math.IsNaN(fhi) {
+		return 0, ErrNoRate
+	}
+
+	// Bisection is slow next to Newton's method but cannot diverge, and
+	// a few hundred iterations over a portfolio's flows are cheap.
+	for i := 0; i < xirrIterations && hi-lo > xirrTolerance; i++ {
+		mid := (lo + hi) / 2
+		fmid := npv(mid)
+		if fmid == 0 {
+			return mid, nil
+		}
+		if (fmid < 0) == (flo < 0) {
+			lo, flo = mid, fmid
+		} else {
+			hi = mid
+		}
+	}
+
+	return (lo + hi) / 2, nil
+}
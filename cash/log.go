@@ -0,0 +1,130 @@
+package cash
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Action is what a policy run decided to do.
+type Action string
+
+const (
+	ActionNone     Action = "none"
+	ActionDeposit  Action = "deposit"
+	ActionWithdraw Action = "withdraw"
+)
+
+// Entry records a single policy run: what the account looked like, what was
+// decided and why. Error is set when the transfer could not be scheduled.
+type Entry struct {
+	Time               time.Time       `json:"time"`
+	InvestorID         int             `json:"investorId"`
+	Cash               decimal.Decimal `json:"cash"`
+	PendingDeposits    decimal.Decimal `json:"pendingDeposits"`
+	PendingWithdrawals decimal.Decimal `json:"pendingWithdrawals"`
+	Action             Action          `json:"action"`
+	Amount             decimal.Decimal `json:"amount"`
+	Reason             string          `json:"reason"`
+	DryRun             bool            `json:"dryRun,omitempty"`
+	Error              string          `json:"error,omitempty"`
+}
+
+// Log is the audit log of a policy. Policies also read it back to tell how
+// long cash has been above the ceiling.
+type Log interface {
+	Append(e Entry) error
+	Entries() ([]Entry, error)
+}
+
+// MemoryLog is a Log that only lives as long as the process.
+type MemoryLog struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func NewMemoryLog() *MemoryLog {
+	return &MemoryLog{}
+}
+
+func (ml *MemoryLog) Append(e Entry) error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	ml.entries = append(ml.entries, e)
+	return nil
+}
+
+func (ml *MemoryLog) Entries() ([]Entry, error) {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	return append([]Entry(nil), ml.entries...), nil
+}
+
+// FileLog is a Log kept as a file of JSON lines, one per entry. Entries are
+// only ever appended, so the file doubles as a human-readable audit trail.
+type FileLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileLog(path string) *FileLog {
+	return &FileLog{path: path}
+}
+
+func (fl *FileLog) Append(e Entry) error {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	bs, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(fl.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(bs, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Entries reads the log back. A log that does not exist yet is empty.
+func (fl *FileLog) Entries() ([]Entry, error) {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	f, err := os.Open(fl.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, scanner.Err()
+}
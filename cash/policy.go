@@ -0,0 +1,230 @@
+/*
+Package cash keeps the idle cash of a Lending Club account inside a band.
+
+A Policy reads the available cash and pending transfers of an account, tops
+it up with AddFunds when cash plus pending deposits falls below the floor
+and sweeps the excess out with WithdrawFunds once cash has stayed above the
+ceiling for a number of days. Both bring cash back to the target. Every run
+is recorded in an audit Log, which the policy also reads back to tell how
+long cash has been above the ceiling, so it should be run regularly, such as
+from a daily cron job.
+*/
+package cash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+)
+
+// Policy keeps the cash of Accounts between Floor and Ceiling.
+type Policy struct {
+	Accounts *lendingclub.AccountsResource
+
+	Floor   decimal.Decimal
+	Ceiling decimal.Decimal
+
+	// Target is the level deposits and withdrawals bring cash back to. It
+	// defaults to halfway between Floor and Ceiling.
+	Target decimal.Decimal
+
+	// SweepAfter is how many calendar days (in Location) cash must stay
+	// above the ceiling before the excess is withdrawn. Zero sweeps on the
+	// first run above it.
+	SweepAfter int
+	Location   *time.Location
+
+	// DryRun decides and logs without scheduling any transfer.
+	DryRun bool
+
+	// Log records every run. It is required.
+	Log Log
+
+	now func() time.Time
+}
+
+// Run checks the account once, schedules a transfer if one is needed and
+// returns the logged entry. An error scheduling the transfer is logged and
+// returned alongside the entry.
+func (p *Policy) Run(ctx context.Context) (*Entry, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	ac, err := p.Accounts.AvailableCashContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := p.Accounts.PendingFundsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Entry{
+		Time:               p.clock(),
+		InvestorID:         ac.InvestorID,
+		Cash:               ac.AvailableCash,
+		PendingDeposits:    decimal.Zero,
+		PendingWithdrawals: decimal.Zero,
+		Action:             ActionNone,
+		Amount:             decimal.Zero,
+		DryRun:             p.DryRun,
+	}
+
+	var withdrawal *lendingclub.Transfer
+	for i, t := range pending {
+		switch t.Operation {
+		case lendingclub.TransferDeposit:
+			e.PendingDeposits = e.PendingDeposits.Add(t.Amount)
+		case lendingclub.TransferWithdrawal:
+			e.PendingWithdrawals = e.PendingWithdrawals.Add(t.Amount)
+			if withdrawal == nil {
+				withdrawal = &pending[i]
+			}
+		}
+	}
+
+	if err := p.decide(e, withdrawal); err != nil {
+		return nil, err
+	}
+
+	var scheduleErr error
+	if !p.DryRun {
+		scheduleErr = p.schedule(ctx, e)
+		if scheduleErr != nil {
+			e.Error = scheduleErr.Error()
+		}
+	}
+
+	if err := p.Log.Append(*e); err != nil {
+		return e, err
+	}
+
+	return e, scheduleErr
+}
+
+func (p *Policy) decide(e *Entry, withdrawal *lendingclub.Transfer) error {
+	target := p.target()
+	funded := e.Cash.Add(e.PendingDeposits)
+
+	switch {
+	case funded.LessThan(p.Floor):
+		e.Action = ActionDeposit
+		e.Amount = target.Sub(funded)
+		e.Reason = fmt.Sprintf("cash %s plus pending deposits %s is below the floor of %s", e.Cash, e.PendingDeposits, p.Floor)
+
+	case e.Cash.Cmp(p.Ceiling) > 0 && withdrawal != nil:
+		e.Reason = fmt.Sprintf("cash %s is above the ceiling of %s but withdrawal %d of %s is already pending",
+			e.Cash, p.Ceiling, withdrawal.TransferID, withdrawal.Amount)
+
+	case e.Cash.Cmp(p.Ceiling) > 0:
+		since, err := p.aboveSince(e)
+		if err != nil {
+			return err
+		}
+
+		days := p.daysBetween(since, e.Time)
+		if days < p.SweepAfter {
+			e.Reason = fmt.Sprintf("cash %s has been above the ceiling of %s for %d of %d days", e.Cash, p.Ceiling, days, p.SweepAfter)
+			return nil
+		}
+
+		e.Action = ActionWithdraw
+		e.Amount = e.Cash.Sub(target)
+		e.Reason = fmt.Sprintf("cash %s has been above the ceiling of %s since %s", e.Cash, p.Ceiling, since.Format("2006-01-02"))
+
+	default:
+		e.Reason = fmt.Sprintf("cash %s (%s with pending deposits) is within %s and %s", e.Cash, funded, p.Floor, p.Ceiling)
+	}
+
+	return nil
+}
+
+// aboveSince returns when the unbroken run of logged entries above the
+// ceiling, ending with e, started.
+func (p *Policy) aboveSince(e *Entry) (time.Time, error) {
+	entries, err := p.Log.Entries()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	since := e.Time
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].InvestorID != e.InvestorID {
+			continue
+		}
+		if entries[i].Cash.Cmp(p.Ceiling) <= 0 {
+			break
+		}
+		since = entries[i].Time
+	}
+
+	return since, nil
+}
+
+// daysBetween counts the calendar days from from to to in p.Location.
+func (p *Policy) daysBetween(from, to time.Time) int {
+	loc := p.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	date := func(t time.Time) time.Time {
+		t = t.In(loc)
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	return int(date(to).Sub(date(from)).Hours() / 24)
+}
+
+func (p *Policy) schedule(ctx context.Context, e *Entry) error {
+	switch e.Action {
+	case ActionDeposit:
+		_, err := p.Accounts.AddFundsContext(ctx, &lendingclub.FundsPayload{
+			Amount:            e.Amount,
+			TransferFrequency: lendingclub.LoadNow,
+		})
+		return err
+	case ActionWithdraw:
+		_, err := p.Accounts.WithdrawFundsContext(ctx, e.Amount)
+		return err
+	}
+
+	return nil
+}
+
+func (p *Policy) validate() error {
+	switch {
+	case p.Accounts == nil:
+		return errors.New("cash: policy has no account")
+	case p.Log == nil:
+		return errors.New("cash: policy has no log")
+	case p.Floor.Sign() < 0 || p.Ceiling.Sign() <= 0 || p.Ceiling.LessThan(p.Floor):
+		return fmt.Errorf("%w: band %s to %s is invalid", lendingclub.ErrValidation, p.Floor, p.Ceiling)
+	case p.Target.Sign() != 0 && (p.Target.LessThan(p.Floor) || p.Ceiling.LessThan(p.Target)):
+		return fmt.Errorf("%w: target %s is outside %s to %s", lendingclub.ErrValidation, p.Target, p.Floor, p.Ceiling)
+	}
+
+	return nil
+}
+
+func (p *Policy) target() decimal.Decimal {
+	if p.Target.Sign() != 0 {
+		return p.Target
+	}
+
+	return p.Floor.Add(p.Ceiling).Div(decimal.New(2, 0))
+}
+
+func (p *Policy) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+
+	return time.Now()
+}
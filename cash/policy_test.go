@@ -0,0 +1,151 @@
+package cash
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/Tonkpils/lendingclub/lctest"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const investorID = 1234
+
+func testPolicy(cash int64) (*Policy, *lctest.Server) {
+	srv := lctest.NewServer()
+	srv.AddAccount(investorID, decimal.New(cash, 0))
+
+	return &Policy{
+		Accounts: srv.Client().Accounts(investorID),
+		Floor:    decimal.New(500, 0),
+		Ceiling:  decimal.New(2000, 0),
+		Log:      NewMemoryLog(),
+	}, srv
+}
+
+func TestPolicyDeposit(t *testing.T) {
+	p, srv := testPolicy(100)
+	defer srv.Close()
+
+	e, err := p.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ActionDeposit, e.Action)
+	assert.Equal(t, "1150", e.Amount.String())
+	assert.Equal(t, "cash 100 plus pending deposits 0 is below the floor of 500", e.Reason)
+
+	pending, err := p.Accounts.PendingFunds(lendingclub.ByOperation(lendingclub.TransferDeposit))
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, lendingclub.LoadNow, pending[0].Frequency)
+
+	// The pending deposit covers the shortfall, so nothing more is added.
+	e, err = p.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ActionNone, e.Action)
+	assert.Equal(t, "1150", e.PendingDeposits.String())
+	assert.Equal(t, "cash 100 (1250 with pending deposits) is within 500 and 2000", e.Reason)
+
+	entries, err := p.Log.Entries()
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestPolicySweep(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cash")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	p, srv := testPolicy(5000)
+	defer srv.Close()
+	p.SweepAfter = 2
+	p.Location = time.UTC
+	p.Log = NewFileLog(filepath.Join(dir, "audit.log"))
+
+	day := time.Date(2015, 6, 5, 9, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return day }
+
+	for i := 0; i < 2; i++ {
+		e, err := p.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, ActionNone, e.Action)
+		day = day.AddDate(0, 0, 1)
+	}
+
+	e, err := p.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ActionWithdraw, e.Action)
+	assert.Equal(t, "3750", e.Amount.String())
+	assert.Equal(t, "cash 5000 has been above the ceiling of 2000 since 2015-06-05", e.Reason)
+	assert.Equal(t, "1250", srv.Cash(investorID).String())
+
+	entries, err := p.Log.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "cash 5000 has been above the ceiling of 2000 for 1 of 2 days", entries[1].Reason)
+	assert.Equal(t, ActionWithdraw, entries[2].Action)
+}
+
+func TestPolicySweepCalendarDays(t *testing.T) {
+	p, srv := testPolicy(5000)
+	defer srv.Close()
+	p.SweepAfter = 1
+	p.Location = time.UTC
+
+	// Two hours apart, but on different days.
+	now := time.Date(2015, 6, 5, 23, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	e, err := p.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ActionNone, e.Action)
+
+	now = now.Add(2 * time.Hour)
+	e, err = p.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ActionWithdraw, e.Action)
+}
+
+func TestPolicyPendingWithdrawal(t *testing.T) {
+	p, srv := testPolicy(10000)
+	defer srv.Close()
+
+	_, err := p.Accounts.WithdrawFunds(decimal.New(100, 0))
+	require.NoError(t, err)
+
+	e, err := p.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ActionNone, e.Action)
+	assert.Equal(t, "100", e.PendingWithdrawals.String())
+	assert.Contains(t, e.Reason, "is already pending")
+}
+
+func TestPolicyDryRun(t *testing.T) {
+	p, srv := testPolicy(100)
+	defer srv.Close()
+	p.DryRun = true
+
+	e, err := p.Run(context.Background())
+	require.NoError(t, err)
+	assert.True(t, e.DryRun)
+	assert.Equal(t, ActionDeposit, e.Action)
+
+	pending, err := p.Accounts.PendingFunds()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestPolicyInvalid(t *testing.T) {
+	p, srv := testPolicy(100)
+	defer srv.Close()
+	p.Target = decimal.New(3000, 0)
+
+	_, err := p.Run(context.Background())
+	assert.True(t, errors.Is(err, lendingclub.ErrValidation))
+}
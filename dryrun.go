@@ -0,0 +1,155 @@
+package lendingclub
+
+import (
+	"log"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DryRun reports whether the client simulates state-changing calls.
+func (c *Client) DryRun() bool {
+	return c.dryRun
+}
+
+// firstDryRunID is where each client starts numbering synthetic orders and
+// portfolios, high enough that they are unlikely to be mistaken for real IDs.
+const firstDryRunID = 900000001
+
+func (c *Client) nextDryRunID() int {
+	return firstDryRunID + int(atomic.AddInt64(&c.dryRunIDs, 1)) - 1
+}
+
+// logDryRun logs the request a dry run skipped. Without a Logger it goes to
+// the standard logger, since a dry run that leaves no trace is pointless.
+func (c *Client) logDryRun(method, urlStr string, payload []byte) {
+	path := urlStr
+	if u, err := url.Parse(urlStr); err == nil {
+		path = u.Path
+	}
+
+	if c.logger == nil {
+		log.Printf("lendingclub: dry run: %s %s %s", method, path, payload)
+		return
+	}
+	c.logf("lendingclub: dry run: %s %s %s", method, path, payload)
+}
+
+func simulateDeposit(investorID int, fp *FundsPayload) (*Deposit, error) {
+	if fp == nil {
+		return nil, invalid("missing funds payload")
+	}
+	if fp.Amount.Sign() <= 0 {
+		return nil, invalid("amount must be positive, got %s", fp.Amount)
+	}
+
+	estimated := Time{Time: time.Now()}
+	if fp.StartDate != nil {
+		estimated = *fp.StartDate
+	}
+
+	return &Deposit{
+		FundsPayload:               *fp,
+		InvestorID:                 investorID,
+		Frequency:                  fp.TransferFrequency,
+		EstimatedFundsTransferDate: estimated,
+	}, nil
+}
+
+func simulateWithdrawal(investorID int, amount decimal.Decimal) (*Withdrawal, error) {
+	if amount.Sign() <= 0 {
+		return nil, invalid("amount must be positive, got %s", amount)
+	}
+
+	return &Withdrawal{
+		Amount:                     amount,
+		InvestorID:                 investorID,
+		EstimatedFundsTransferDate: Time{Time: time.Now()},
+	}, nil
+}
+
+func simulateCancellation(investorID int, transferIDs []int) (*CancellationResult, error) {
+	if len(transferIDs) == 0 {
+		return nil, invalid("no transfers to cancel")
+	}
+
+	cr := &CancellationResult{InvestorID: investorID}
+	for _, id := range transferIDs {
+		cr.Cancellations = append(cr.Cancellations, Cancellation{
+			TransferID: id,
+			Status:     "SUCCESS",
+			Message:    "dry run",
+		})
+	}
+
+	return cr, nil
+}
+
+func simulatePortfolio(portfolioID int, name, description string) (*Portfolio, error) {
+	if name == "" {
+		return nil, invalid("missing portfolio name")
+	}
+
+	return &Portfolio{ID: portfolioID, Name: name, Description: description}, nil
+}
+
+func simulateAssignment(portfolioID int, notes []Note) ([]NoteAssignment, error) {
+	if portfolioID <= 0 {
+		return nil, invalid("invalid portfolio ID %d", portfolioID)
+	}
+
+	assignments := make([]NoteAssignment, len(notes))
+	for i, note := range notes {
+		assignments[i] = NoteAssignment{
+			NoteID:  note.ID,
+			OrderID: note.OrderID,
+			LoanID:  note.LoanID,
+			Status:  "SUCCESS",
+			Message: "dry run",
+		}
+	}
+
+	return assignments, nil
+}
+
+func simulateOrder(id int, orders []OrderSubmission) (*OrderInstruct, error) {
+	if len(orders) == 0 {
+		return nil, invalid("no orders to submit")
+	}
+
+	instruct := &OrderInstruct{ID: id}
+	for _, o := range orders {
+		if o.LoanID <= 0 {
+			return nil, invalid("invalid loan ID %d", o.LoanID)
+		}
+		if o.Amount.Sign() <= 0 {
+			return nil, invalid("loan %d: amount must be positive, got %s", o.LoanID, o.Amount)
+		}
+
+		instruct.OrderConfirmations = append(instruct.OrderConfirmations, OrderConfirmation{
+			LoanID:          o.LoanID,
+			RequestedAmount: o.Amount,
+			InvestedAmount:  o.Amount,
+			ExecutionStatus: ExecutionStatuses{OrderFulfilled},
+		})
+	}
+
+	return instruct, nil
+}
+
+func simulateTrade(confirmations []TradeConfirmation) (*TradeResult, error) {
+	if len(confirmations) == 0 {
+		return nil, invalid("no notes to trade")
+	}
+
+	for i, tc := range confirmations {
+		if tc.Price.Sign() < 0 {
+			return nil, invalid("note %d: price must not be negative, got %s", tc.NoteID, tc.Price)
+		}
+		confirmations[i].ExecutionStatus = "SUCCESS"
+	}
+
+	return &TradeResult{Status: "SUCCESS", Confirmations: confirmations}, nil
+}
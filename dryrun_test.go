@@ -0,0 +1,109 @@
+package lendingclub
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		assert.Equal(t, "GET", req.Method)
+
+		err := respondWithFixture(w, "available_cash.json")
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	c := NewClientWithOptions("Token", WithBaseURL(ts.URL), WithDryRun(), WithLogger(log.New(&logs, "", 0)))
+	require.True(t, c.DryRun())
+	ar := c.Accounts(TestAccountID)
+
+	// Reads still hit the API.
+	_, err := ar.AvailableCash()
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+	instruct, err := ar.SubmitOrder([]OrderSubmission{
+		{LoanID: 50001, Amount: decimal.NewFromFloat(50)},
+		{LoanID: 50002, Amount: decimal.NewFromFloat(25)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 900000001, instruct.ID)
+	require.Len(t, instruct.OrderConfirmations, 2)
+	assert.Equal(t, "50", instruct.OrderConfirmations[0].InvestedAmount.String())
+	assert.Equal(t, ExecutionStatuses{OrderFulfilled}, instruct.OrderConfirmations[1].ExecutionStatus)
+
+	deposit, err := ar.AddFunds(&FundsPayload{Amount: decimal.NewFromFloat(100), TransferFrequency: LoadNow})
+	require.NoError(t, err)
+	assert.Equal(t, TestAccountID, deposit.InvestorID)
+	assert.Equal(t, LoadNow, deposit.Frequency)
+
+	withdrawal, err := ar.WithdrawFunds(decimal.NewFromFloat(10))
+	require.NoError(t, err)
+	assert.Equal(t, "10", withdrawal.Amount.String())
+
+	cr, err := ar.CancelFunds([]int{1, 2})
+	require.NoError(t, err)
+	assert.Len(t, cr.Cancellations, 2)
+
+	portfolio, err := ar.CreatePortfolio("Test", "")
+	require.NoError(t, err)
+	assert.Equal(t, 900000002, portfolio.ID)
+	assert.Equal(t, "Test", portfolio.Name)
+
+	result, err := c.Folio(TestAccountID).Buy([]NoteBid{{LoanID: 1, NoteID: 2, OrderID: 3, BidPrice: decimal.NewFromFloat(20)}})
+	require.NoError(t, err)
+	require.Len(t, result.Confirmations, 1)
+	assert.Equal(t, "SUCCESS", result.Confirmations[0].ExecutionStatus)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+	assert.Contains(t, logs.String(), "dry run: POST /accounts/1234/orders")
+	assert.Contains(t, logs.String(), "dry run: POST /accounts/1234/funds/add")
+	assert.Contains(t, logs.String(), "dry run: POST /accounts/1234/trades/buy")
+}
+
+func TestDryRunDefaultLogger(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	// Each client numbers its own synthetic IDs.
+	for i := 0; i < 2; i++ {
+		c := NewClientWithOptions("Token", WithBaseURL("http://localhost:0"), WithDryRun())
+		instruct, err := c.Accounts(TestAccountID).SubmitOrder([]OrderSubmission{{LoanID: 50001, Amount: decimal.New(25, 0)}})
+		require.NoError(t, err)
+		assert.Equal(t, 900000001, instruct.ID)
+	}
+
+	assert.Contains(t, logs.String(), "lendingclub: dry run: POST /accounts/1234/orders")
+}
+
+func TestDryRunValidation(t *testing.T) {
+	c := NewClientWithOptions("Token", WithBaseURL("http://localhost:0"), WithDryRun())
+	ar := c.Accounts(TestAccountID)
+
+	_, err := ar.SubmitOrder([]OrderSubmission{{LoanID: 50001, Amount: decimal.Zero}})
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	_, err = ar.WithdrawFunds(decimal.NewFromFloat(-5))
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	_, err = ar.AddFunds(&FundsPayload{Amount: decimal.NewFromFloat(100)})
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	_, err = ar.CreatePortfolio("", "")
+	assert.True(t, errors.Is(err, ErrValidation))
+}
@@ -0,0 +1,529 @@
+package lendingclub
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Lending Club reports many attributes as string codes. Each code has its own
+// type with constants for the known values. Decoding JSON canonicalizes the
+// case of known codes and keeps unknown codes as they are, so new codes added
+// by Lending Club do not break decoding. The ParseXxx functions and Validate
+// methods reject unknown codes; request payloads, such as FundsPayload,
+// validate theirs before they are sent.
+
+// enum is the set of known codes of an enum type.
+type enum struct {
+	name    string
+	codes   []string
+	aliases map[string]string
+}
+
+func newEnum(name string, codes ...string) enum {
+	return enum{name: name, codes: codes}
+}
+
+// withAliases returns e accepting other spellings of its codes. aliases maps
+// each spelling, in any case, to its code.
+func (e enum) withAliases(aliases map[string]string) enum {
+	e.aliases = aliases
+	return e
+}
+
+// lookup returns the canonical form of code, ignoring case.
+func (e enum) lookup(code string) (string, bool) {
+	for _, c := range e.codes {
+		if strings.EqualFold(c, code) {
+			return c, true
+		}
+	}
+	for alias, c := range e.aliases {
+		if strings.EqualFold(alias, code) {
+			return c, true
+		}
+	}
+
+	return code, false
+}
+
+func (e enum) parse(code string) (string, error) {
+	c, ok := e.lookup(strings.TrimSpace(code))
+	if !ok {
+		return code, invalid("unknown %s %q", e.name, code)
+	}
+
+	return c, nil
+}
+
+func (e enum) known(code string) bool {
+	_, ok := e.lookup(code)
+	return ok
+}
+
+func (e enum) validate(code string) error {
+	_, err := e.parse(code)
+	return err
+}
+
+func (e enum) unmarshal(b []byte) (string, error) {
+	var code *string
+	if err := json.Unmarshal(b, &code); err != nil || code == nil {
+		return "", err
+	}
+
+	c, _ := e.lookup(*code)
+	return c, nil
+}
+
+// TransferFrequency is how often a funds transfer recurs.
+type TransferFrequency string
+
+const (
+	LoadNow         TransferFrequency = "LOAD_NOW"
+	LoadOnce        TransferFrequency = "LOAD_ONCE"
+	LoadWeekly      TransferFrequency = "LOAD_WEEKLY"
+	LoadBiweekly    TransferFrequency = "LOAD_BIWEEKLY"
+	LoadOnDay1And16 TransferFrequency = "LOAD_ON_DAY_1_AND_16"
+	LoadMonthly     TransferFrequency = "LOAD_MONTHLY"
+)
+
+var transferFrequencies = newEnum("transfer frequency",
+	string(LoadNow), string(LoadOnce), string(LoadWeekly), string(LoadBiweekly), string(LoadOnDay1And16), string(LoadMonthly))
+
+func ParseTransferFrequency(s string) (TransferFrequency, error) {
+	c, err := transferFrequencies.parse(s)
+	return TransferFrequency(c), err
+}
+
+func (f TransferFrequency) String() string  { return string(f) }
+func (f TransferFrequency) Known() bool     { return transferFrequencies.known(string(f)) }
+func (f TransferFrequency) Validate() error { return transferFrequencies.validate(string(f)) }
+
+func (f *TransferFrequency) UnmarshalJSON(b []byte) error {
+	c, err := transferFrequencies.unmarshal(b)
+	*f = TransferFrequency(c)
+	return err
+}
+
+// TransferStatus is the state of a pending transfer.
+type TransferStatus string
+
+const (
+	TransferPending   TransferStatus = "PENDING"
+	TransferScheduled TransferStatus = "SCHEDULED"
+)
+
+var transferStatuses = newEnum("transfer status", string(TransferPending), string(TransferScheduled))
+
+func ParseTransferStatus(s string) (TransferStatus, error) {
+	c, err := transferStatuses.parse(s)
+	return TransferStatus(c), err
+}
+
+func (s TransferStatus) String() string  { return string(s) }
+func (s TransferStatus) Known() bool     { return transferStatuses.known(string(s)) }
+func (s TransferStatus) Validate() error { return transferStatuses.validate(string(s)) }
+
+func (s *TransferStatus) UnmarshalJSON(b []byte) error {
+	c, err := transferStatuses.unmarshal(b)
+	*s = TransferStatus(c)
+	return err
+}
+
+// TransferOperation is the direction of a transfer.
+type TransferOperation string
+
+const (
+	TransferDeposit    TransferOperation = "ADD"
+	TransferWithdrawal TransferOperation = "WITHDRAW"
+)
+
+var transferOperations = newEnum("transfer operation", string(TransferDeposit), string(TransferWithdrawal))
+
+func ParseTransferOperation(s string) (TransferOperation, error) {
+	c, err := transferOperations.parse(s)
+	return TransferOperation(c), err
+}
+
+func (o TransferOperation) String() string  { return string(o) }
+func (o TransferOperation) Known() bool     { return transferOperations.known(string(o)) }
+func (o TransferOperation) Validate() error { return transferOperations.validate(string(o)) }
+
+func (o *TransferOperation) UnmarshalJSON(b []byte) error {
+	c, err := transferOperations.unmarshal(b)
+	*o = TransferOperation(c)
+	return err
+}
+
+// ExecutionStatus is a code describing the outcome of an order.
+type ExecutionStatus string
+
+const (
+	OrderFulfilled            ExecutionStatus = "ORDER_FULFILLED"
+	OrderLoanAmountExceeded   ExecutionStatus = "LOAN_AMNT_EXCEEDED"
+	OrderInvalidInvestment    ExecutionStatus = "NOT_A_VALID_INVESTMENT"
+	OrderNotInFunding         ExecutionStatus = "NOT_AN_IN_FUNDING_LOAN"
+	OrderInsufficientCash     ExecutionStatus = "INSUFFICIENT_CASH"
+	OrderAmountLow            ExecutionStatus = "REQUESTED_AMNT_LOW"
+	OrderAmountRounded        ExecutionStatus = "REQUESTED_AMNT_ROUNDED"
+	OrderAugmentedByMerge     ExecutionStatus = "AUGMENTED_BY_MERGE"
+	OrderNoteAddedToPortfolio ExecutionStatus = "NOTE_ADDED_TO_PORTFOLIO"
+	OrderInvalidPortfolio     ExecutionStatus = "NOT_A_VALID_PORTFOLIO"
+	OrderPortfolioError       ExecutionStatus = "ERROR_ADDING_NOTE_TO_PORTFOLIO"
+	OrderSystemBusy           ExecutionStatus = "SYSTEM_BUSY"
+	OrderUnknownError         ExecutionStatus = "UNKNOWN_ERROR"
+)
+
+var executionStatuses = newEnum("execution status",
+	string(OrderFulfilled), string(OrderLoanAmountExceeded), string(OrderInvalidInvestment),
+	string(OrderNotInFunding), string(OrderInsufficientCash), string(OrderAmountLow),
+	string(OrderAmountRounded), string(OrderAugmentedByMerge), string(OrderNoteAddedToPortfolio),
+	string(OrderInvalidPortfolio), string(OrderPortfolioError), string(OrderSystemBusy),
+	string(OrderUnknownError)).withAliases(map[string]string{
+	// Lending Club has spelled this code both ways.
+	"NOT_AN_INFUNDING_LOAN": string(OrderNotInFunding),
+})
+
+func ParseExecutionStatus(s string) (ExecutionStatus, error) {
+	c, err := executionStatuses.parse(s)
+	return ExecutionStatus(c), err
+}
+
+func (s ExecutionStatus) String() string  { return string(s) }
+func (s ExecutionStatus) Known() bool     { return executionStatuses.known(string(s)) }
+func (s ExecutionStatus) Validate() error { return executionStatuses.validate(string(s)) }
+
+func (s *ExecutionStatus) UnmarshalJSON(b []byte) error {
+	c, err := executionStatuses.unmarshal(b)
+	*s = ExecutionStatus(c)
+	return err
+}
+
+// ExecutionStatuses are the codes reported for a single order, such as
+// ORDER_FULFILLED alongside LOAN_AMNT_EXCEEDED for a partial fill. They are
+// sent as a list; a comma-separated string is accepted as well.
+type ExecutionStatuses []ExecutionStatus
+
+// Has reports whether status is among the codes.
+func (ss ExecutionStatuses) Has(status ExecutionStatus) bool {
+	for _, s := range ss {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ss ExecutionStatuses) String() string {
+	codes := make([]string, len(ss))
+	for i, s := range ss {
+		codes[i] = string(s)
+	}
+
+	return strings.Join(codes, ",")
+}
+
+func (ss *ExecutionStatuses) UnmarshalJSON(b []byte) error {
+	var list []ExecutionStatus
+	if err := json.Unmarshal(b, &list); err == nil {
+		*ss = list
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	*ss = nil
+	for _, code := range strings.Split(s, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			c, _ := executionStatuses.lookup(code)
+			*ss = append(*ss, ExecutionStatus(c))
+		}
+	}
+
+	return nil
+}
+
+// LoanStatus is the state of a loan a note belongs to.
+type LoanStatus string
+
+const (
+	LoanInFunding     LoanStatus = "In Funding"
+	LoanIssuing       LoanStatus = "Issuing"
+	LoanIssued        LoanStatus = "Issued"
+	LoanCurrent       LoanStatus = "Current"
+	LoanInGracePeriod LoanStatus = "In Grace Period"
+	LoanLate16To30    LoanStatus = "Late (16-30 days)"
+	LoanLate31To120   LoanStatus = "Late (31-120 days)"
+	LoanDefault       LoanStatus = "Default"
+	LoanChargedOff    LoanStatus = "Charged Off"
+	LoanFullyPaid     LoanStatus = "Fully Paid"
+)
+
+var loanStatuses = newEnum("loan status",
+	string(LoanInFunding), string(LoanIssuing), string(LoanIssued), string(LoanCurrent),
+	string(LoanInGracePeriod), string(LoanLate16To30), string(LoanLate31To120),
+	string(LoanDefault), string(LoanChargedOff), string(LoanFullyPaid))
+
+func ParseLoanStatus(s string) (LoanStatus, error) {
+	c, err := loanStatuses.parse(s)
+	return LoanStatus(c), err
+}
+
+func (s LoanStatus) String() string  { return string(s) }
+func (s LoanStatus) Known() bool     { return loanStatuses.known(string(s)) }
+func (s LoanStatus) Validate() error { return loanStatuses.validate(string(s)) }
+
+func (s *LoanStatus) UnmarshalJSON(b []byte) error {
+	c, err := loanStatuses.unmarshal(b)
+	*s = LoanStatus(c)
+	return err
+}
+
+// Grade is a loan's credit grade, A through G.
+type Grade string
+
+const (
+	GradeA Grade = "A"
+	GradeB Grade = "B"
+	GradeC Grade = "C"
+	GradeD Grade = "D"
+	GradeE Grade = "E"
+	GradeF Grade = "F"
+	GradeG Grade = "G"
+)
+
+var grades = newEnum("grade",
+	string(GradeA), string(GradeB), string(GradeC), string(GradeD), string(GradeE), string(GradeF), string(GradeG))
+
+func ParseGrade(s string) (Grade, error) {
+	c, err := grades.parse(s)
+	return Grade(c), err
+}
+
+func (g Grade) String() string  { return string(g) }
+func (g Grade) Known() bool     { return grades.known(string(g)) }
+func (g Grade) Validate() error { return grades.validate(string(g)) }
+
+func (g *Grade) UnmarshalJSON(b []byte) error {
+	c, err := grades.unmarshal(b)
+	*g = Grade(c)
+	return err
+}
+
+// SubGrade refines a Grade with a level from 1 to 5, such as "B3".
+type SubGrade string
+
+func ParseSubGrade(s string) (SubGrade, error) {
+	sg := SubGrade(strings.ToUpper(strings.TrimSpace(s)))
+	if !sg.Known() {
+		return SubGrade(s), invalid("unknown sub-grade %q", s)
+	}
+
+	return sg, nil
+}
+
+func (sg SubGrade) String() string { return string(sg) }
+
+func (sg SubGrade) Known() bool {
+	return len(sg) == 2 && sg.Grade().Known() && sg[1] >= '1' && sg[1] <= '5'
+}
+
+func (sg SubGrade) Validate() error {
+	_, err := ParseSubGrade(string(sg))
+	return err
+}
+
+// Grade returns the grade of sg, or "" if sg is empty.
+func (sg SubGrade) Grade() Grade {
+	if sg == "" {
+		return ""
+	}
+
+	return Grade(sg[:1])
+}
+
+func (sg *SubGrade) UnmarshalJSON(b []byte) error {
+	var s *string
+	if err := json.Unmarshal(b, &s); err != nil || s == nil {
+		return err
+	}
+
+	*sg = SubGrade(*s)
+	if upper := SubGrade(strings.ToUpper(*s)); upper.Known() {
+		*sg = upper
+	}
+
+	return nil
+}
+
+// HomeOwnership is a borrower's home ownership status.
+type HomeOwnership string
+
+const (
+	HomeRent     HomeOwnership = "RENT"
+	HomeOwn      HomeOwnership = "OWN"
+	HomeMortgage HomeOwnership = "MORTGAGE"
+	HomeOther    HomeOwnership = "OTHER"
+	HomeNone     HomeOwnership = "NONE"
+	HomeAny      HomeOwnership = "ANY"
+)
+
+var homeOwnerships = newEnum("home ownership",
+	string(HomeRent), string(HomeOwn), string(HomeMortgage), string(HomeOther), string(HomeNone), string(HomeAny))
+
+func ParseHomeOwnership(s string) (HomeOwnership, error) {
+	c, err := homeOwnerships.parse(s)
+	return HomeOwnership(c), err
+}
+
+func (h HomeOwnership) String() string  { return string(h) }
+func (h HomeOwnership) Known() bool     { return homeOwnerships.known(string(h)) }
+func (h HomeOwnership) Validate() error { return homeOwnerships.validate(string(h)) }
+
+func (h *HomeOwnership) UnmarshalJSON(b []byte) error {
+	c, err := homeOwnerships.unmarshal(b)
+	*h = HomeOwnership(c)
+	return err
+}
+
+// IncomeVerification is whether and how a borrower's income was verified.
+type IncomeVerification string
+
+const (
+	IncomeNotVerified    IncomeVerification = "NOT_VERIFIED"
+	IncomeSourceVerified IncomeVerification = "SOURCE_VERIFIED"
+	IncomeVerified       IncomeVerification = "VERIFIED"
+)
+
+var incomeVerifications = newEnum("income verification",
+	string(IncomeNotVerified), string(IncomeSourceVerified), string(IncomeVerified))
+
+func ParseIncomeVerification(s string) (IncomeVerification, error) {
+	c, err := incomeVerifications.parse(s)
+	return IncomeVerification(c), err
+}
+
+func (v IncomeVerification) String() string  { return string(v) }
+func (v IncomeVerification) Known() bool     { return incomeVerifications.known(string(v)) }
+func (v IncomeVerification) Validate() error { return incomeVerifications.validate(string(v)) }
+
+func (v *IncomeVerification) UnmarshalJSON(b []byte) error {
+	c, err := incomeVerifications.unmarshal(b)
+	*v = IncomeVerification(c)
+	return err
+}
+
+// ListStatus is the pool a loan is initially listed in: fractional loans are
+// open to notes, whole loans are sold in full.
+type ListStatus string
+
+const (
+	ListFractional ListStatus = "F"
+	ListWhole      ListStatus = "W"
+)
+
+var listStatuses = newEnum("list status", string(ListFractional), string(ListWhole))
+
+func ParseListStatus(s string) (ListStatus, error) {
+	c, err := listStatuses.parse(s)
+	return ListStatus(c), err
+}
+
+func (s ListStatus) String() string  { return string(s) }
+func (s ListStatus) Known() bool     { return listStatuses.known(string(s)) }
+func (s ListStatus) Validate() error { return listStatuses.validate(string(s)) }
+
+func (s *ListStatus) UnmarshalJSON(b []byte) error {
+	c, err := listStatuses.unmarshal(b)
+	*s = ListStatus(c)
+	return err
+}
+
+// ApplicationType is whether a loan has one borrower or joint borrowers.
+type ApplicationType string
+
+const (
+	ApplicationIndividual ApplicationType = "INDIVIDUAL"
+	ApplicationJoint      ApplicationType = "JOINT"
+	ApplicationDirectPay  ApplicationType = "DIRECT_PAY"
+)
+
+var applicationTypes = newEnum("application type",
+	string(ApplicationIndividual), string(ApplicationJoint), string(ApplicationDirectPay))
+
+func ParseApplicationType(s string) (ApplicationType, error) {
+	c, err := applicationTypes.parse(s)
+	return ApplicationType(c), err
+}
+
+func (a ApplicationType) String() string  { return string(a) }
+func (a ApplicationType) Known() bool     { return applicationTypes.known(string(a)) }
+func (a ApplicationType) Validate() error { return applicationTypes.validate(string(a)) }
+
+func (a *ApplicationType) UnmarshalJSON(b []byte) error {
+	c, err := applicationTypes.unmarshal(b)
+	*a = ApplicationType(c)
+	return err
+}
+
+// Purpose is what a borrower intends to use a loan for.
+type Purpose string
+
+const (
+	PurposeDebtConsolidation Purpose = "debt_consolidation"
+	PurposeCreditCard        Purpose = "credit_card"
+	PurposeHomeImprovement   Purpose = "home_improvement"
+	PurposeMajorPurchase     Purpose = "major_purchase"
+	PurposeSmallBusiness     Purpose = "small_business"
+	PurposeCar               Purpose = "car"
+	PurposeMedical           Purpose = "medical"
+	PurposeMoving            Purpose = "moving"
+	PurposeVacation          Purpose = "vacation"
+	PurposeHouse             Purpose = "house"
+	PurposeWedding           Purpose = "wedding"
+	PurposeRenewableEnergy   Purpose = "renewable_energy"
+	PurposeEducational       Purpose = "educational"
+	PurposeOther             Purpose = "other"
+)
+
+var purposes = newEnum("purpose",
+	string(PurposeDebtConsolidation), string(PurposeCreditCard), string(PurposeHomeImprovement),
+	string(PurposeMajorPurchase), string(PurposeSmallBusiness), string(PurposeCar),
+	string(PurposeMedical), string(PurposeMoving), string(PurposeVacation), string(PurposeHouse),
+	string(PurposeWedding), string(PurposeRenewableEnergy), string(PurposeEducational),
+	string(PurposeOther)).withAliases(purposeTitles)
+
+// purposeTitles are the purposes as the notes endpoints spell them.
+var purposeTitles = map[string]string{
+	"Debt consolidation":      string(PurposeDebtConsolidation),
+	"Credit card refinancing": string(PurposeCreditCard),
+	"Home improvement":        string(PurposeHomeImprovement),
+	"Major purchase":          string(PurposeMajorPurchase),
+	"Business":                string(PurposeSmallBusiness),
+	"Small business":          string(PurposeSmallBusiness),
+	"Car financing":           string(PurposeCar),
+	"Medical expenses":        string(PurposeMedical),
+	"Moving and relocation":   string(PurposeMoving),
+	"Home buying":             string(PurposeHouse),
+	"Green loan":              string(PurposeRenewableEnergy),
+	"Learning and training":   string(PurposeEducational),
+}
+
+func ParsePurpose(s string) (Purpose, error) {
+	c, err := purposes.parse(s)
+	return Purpose(c), err
+}
+
+func (p Purpose) String() string  { return string(p) }
+func (p Purpose) Known() bool     { return purposes.known(string(p)) }
+func (p Purpose) Validate() error { return purposes.validate(string(p)) }
+
+func (p *Purpose) UnmarshalJSON(b []byte) error {
+	c, err := purposes.unmarshal(b)
+	*p = Purpose(c)
+	return err
+}
@@ -0,0 +1,100 @@
+package lendingclub
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnums(t *testing.T) {
+	f, err := ParseTransferFrequency("load_monthly")
+	require.NoError(t, err)
+	assert.Equal(t, LoadMonthly, f)
+
+	_, err = ParseTransferFrequency("LOAD_NOWW")
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	sg, err := ParseSubGrade("c4")
+	require.NoError(t, err)
+	assert.Equal(t, SubGrade("C4"), sg)
+	assert.Equal(t, GradeC, sg.Grade())
+
+	_, err = ParseSubGrade("H1")
+	assert.Error(t, err)
+
+	p, err := ParsePurpose("credit_card")
+	require.NoError(t, err)
+	assert.Equal(t, "credit_card", p.String())
+
+	p, err = ParsePurpose("Credit card refinancing")
+	require.NoError(t, err)
+	assert.Equal(t, PurposeCreditCard, p)
+}
+
+func TestEnumJSON(t *testing.T) {
+	var loan Loan
+	err := json.Unmarshal([]byte(`{
+		"grade": "b",
+		"subGrade": "b2",
+		"homeOwnership": "mortgage",
+		"isIncV": "PARTIALLY_VERIFIED",
+		"purpose": "space_travel",
+		"initialListStatus": null
+	}`), &loan)
+	require.NoError(t, err)
+
+	assert.Equal(t, GradeB, loan.Grade)
+	assert.Equal(t, SubGrade("B2"), loan.SubGrade)
+	assert.Equal(t, HomeMortgage, loan.HomeOwnership)
+	assert.Equal(t, ListStatus(""), loan.InitialListStatus)
+
+	// Codes Lending Club adds later survive a round trip.
+	assert.False(t, loan.IsIncomeVerified.Known())
+	assert.Equal(t, IncomeVerification("PARTIALLY_VERIFIED"), loan.IsIncomeVerified)
+	assert.Equal(t, Purpose("space_travel"), loan.Purpose)
+
+	b, err := json.Marshal(Transfer{Frequency: "LOAD_QUARTERLY"})
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"frequency":"LOAD_QUARTERLY"`)
+
+	// Notes decode to typed grades and purposes, whichever way they are
+	// spelled.
+	var note DetailedNote
+	require.NoError(t, json.Unmarshal([]byte(`{"grade": "d4", "purpose": "Home improvement"}`), &note))
+	assert.Equal(t, SubGrade("D4"), note.Grade)
+	assert.Equal(t, PurposeHomeImprovement, note.Purpose)
+
+	var status ExecutionStatus
+	require.NoError(t, json.Unmarshal([]byte(`"NOT_AN_INFUNDING_LOAN"`), &status))
+	assert.Equal(t, OrderNotInFunding, status)
+}
+
+func TestEnumValidate(t *testing.T) {
+	assert.NoError(t, LoadNow.Validate())
+	assert.NoError(t, TransferDeposit.Validate())
+	assert.NoError(t, SubGrade("A1").Validate())
+	assert.NoError(t, PurposeCar.Validate())
+	assert.NoError(t, ExecutionStatus("NOT_AN_IN_FUNDING_LOAN").Validate())
+
+	for _, err := range []error{
+		TransferOperation("MOVE").Validate(),
+		TransferStatus("DONE").Validate(),
+		Grade("H").Validate(),
+		SubGrade("A6").Validate(),
+		Purpose("space_travel").Validate(),
+		HomeOwnership("CASTLE").Validate(),
+	} {
+		assert.True(t, errors.Is(err, ErrValidation), err)
+	}
+}
+
+func TestAddFundsInvalidFrequency(t *testing.T) {
+	ar := NewClientWithOptions("Token", WithBaseURL("http://127.0.0.1:0")).Accounts(TestAccountID)
+
+	_, err := ar.AddFunds(&FundsPayload{Amount: decimal.New(100, 0), TransferFrequency: "LOAD_NOWW"})
+	assert.True(t, errors.Is(err, ErrValidation))
+}
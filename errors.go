@@ -0,0 +1,118 @@
+package lendingclub
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Error kinds that an *ErrorResponse can be matched against with errors.Is.
+var (
+	ErrUnauthorized    = errors.New("lendingclub: unauthorized")
+	ErrForbidden       = errors.New("lendingclub: forbidden")
+	ErrNotFound        = errors.New("lendingclub: not found")
+	ErrValidation      = errors.New("lendingclub: validation failed")
+	ErrTooManyRequests = errors.New("lendingclub: too many requests")
+	ErrServer          = errors.New("lendingclub: server error")
+)
+
+// ErrNotModified is returned by conditional requests when the resource has not
+// changed since the given time.
+var ErrNotModified = errors.New("lendingclub: not modified")
+
+// invalid returns an error matching ErrValidation with the formatted reason.
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: "+format, append([]interface{}{ErrValidation}, args...)...)
+}
+
+// maxErrorBodySize caps how much of an error response body is read.
+const maxErrorBodySize = 1 << 20
+
+// ErrorResponse is returned for every non-2xx response from the API. It
+// carries the HTTP status, the request that caused it and the field-level
+// errors reported by Lending Club, if any.
+type ErrorResponse struct {
+	StatusCode int        `json:"-"`
+	Status     string     `json:"-"`
+	Method     string     `json:"-"`
+	Path       string     `json:"-"`
+	Errors     []APIError `json:"errors"`
+}
+
+func newErrorResponse(res *http.Response) *ErrorResponse {
+	er := &ErrorResponse{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+	}
+	if res.Request != nil {
+		er.Method = res.Request.Method
+		er.Path = res.Request.URL.Path
+	}
+
+	// The body is not guaranteed to be JSON (e.g. a proxy error page), in
+	// which case the error is still returned without field-level details.
+	bs, err := ioutil.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+	if err == nil && len(bs) > 0 {
+		json.Unmarshal(bs, er)
+	}
+
+	return er
+}
+
+func (e *ErrorResponse) Error() string {
+	msg := fmt.Sprintf("lendingclub: %s %s: %s", e.Method, e.Path, e.Status)
+	if len(e.Errors) == 0 {
+		return msg
+	}
+
+	errs := make([]string, len(e.Errors))
+	for i, apiErr := range e.Errors {
+		errs[i] = apiErr.Error()
+	}
+
+	return msg + ": " + strings.Join(errs, "; ")
+}
+
+// Is reports whether the response matches one of the package's error kinds.
+func (e *ErrorResponse) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrTooManyRequests:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+
+	return false
+}
+
+// APIError is a single error reported by Lending Club, usually tied to a
+// field of the request payload.
+type APIError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e APIError) Error() string {
+	msg := e.Message
+	if e.Code != "" {
+		msg += " (" + e.Code + ")"
+	}
+	if e.Field != "" {
+		msg = e.Field + ": " + msg
+	}
+
+	return msg
+}
@@ -0,0 +1,72 @@
+package lendingclub
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorResponseValidation(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		err := respondWithFixture(w, "validation_error.json")
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	ar := newClient(ts.URL, "Token", nil).Accounts(TestAccountID)
+	_, err := ar.WithdrawFunds(decimal.NewFromFloat(100))
+	require.Error(t, err)
+
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.False(t, errors.Is(err, ErrServer))
+
+	var errResp *ErrorResponse
+	require.True(t, errors.As(err, &errResp))
+	assert.Equal(t, http.StatusBadRequest, errResp.StatusCode)
+	assert.Equal(t, "POST", errResp.Method)
+	assert.Equal(t, fmt.Sprintf("/accounts/%d/funds/withdraw", TestAccountID), errResp.Path)
+	require.Len(t, errResp.Errors, 1)
+	assert.Equal(t, "amount", errResp.Errors[0].Field)
+	assert.Equal(t, "insufficient-balance", errResp.Errors[0].Code)
+	assert.Equal(t, "Not enough cash available to withdraw", errResp.Errors[0].Message)
+}
+
+func TestErrorResponseKinds(t *testing.T) {
+	cases := []struct {
+		status int
+		kind   error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusTooManyRequests, ErrTooManyRequests},
+		{http.StatusInternalServerError, ErrServer},
+		{http.StatusServiceUnavailable, ErrServer},
+	}
+
+	for _, c := range cases {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			http.Error(w, http.StatusText(c.status), c.status)
+		}))
+
+		client := newClient(ts.URL, "Token", nil)
+		client.Retry = nil
+		_, err := client.Accounts(TestAccountID).Summary()
+		ts.Close()
+
+		assert.True(t, errors.Is(err, c.kind), "status %d should match %v", c.status, c.kind)
+
+		var errResp *ErrorResponse
+		require.True(t, errors.As(err, &errResp))
+		assert.Equal(t, c.status, errResp.StatusCode)
+		assert.Equal(t, "GET", errResp.Method)
+		assert.Empty(t, errResp.Errors)
+	}
+}
@@ -4,16 +4,23 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"time"
+	"strconv"
 
 	"github.com/Tonkpils/lendingclub"
 )
 
 func main() {
 	apiKey := os.Getenv("LC_KEY")
-	accountID := os.Getenv("LC_ACCOUNT_ID")
-	c := lendingclub.NewClient(apiKey, nil)
-	ar := c.Accounts(accoutnID)
+	accountID, err := strconv.Atoi(os.Getenv("LC_ACCOUNT_ID"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	c := lendingclub.NewClientWithOptions(apiKey,
+		lendingclub.WithRateLimiter(lendingclub.NewRateLimiter()),
+		lendingclub.WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+	ar := c.Accounts(accountID)
 	sum, err := ar.Summary()
 	if err != nil {
 		log.Fatal(err)
@@ -21,8 +28,6 @@ func main() {
 
 	fmt.Printf("%+v\n", sum)
 
-	time.Sleep(1 * time.Second)
-
 	ac, err := ar.AvailableCash()
 	if err != nil {
 		log.Fatal(err)
@@ -30,12 +35,11 @@ func main() {
 
 	fmt.Printf("%+v\n", ac)
 
-	// fp := &lendingclub.FundsPayload{
-	// 	Amount:            decimal.New(100, 1),
-	// 	TransferFrequency: "LOAD_NOW",
+	// fp, err := lendingclub.DepositNow(decimal.New(100, 1))
+	// if err != nil {
+	// 	log.Fatal(err)
 	// }
 
-	// time.Sleep(1 * time.Second)
 	// fr, err := ar.AddFunds(fp)
 	// if err != nil {
 	// 	log.Fatal(err)
@@ -43,7 +47,6 @@ func main() {
 
 	// fmt.Printf("%+v\n", fr)
 
-	// time.Sleep(1 * time.Second)
 	// wd, err := ar.WithdrawFunds(decimal.New(100, 0))
 	// if err != nil {
 	// 	log.Fatal(err)
@@ -51,7 +54,7 @@ func main() {
 
 	// fmt.Printf("%+v\n", wd)
 
-	ts, err := ar.PendingFunds()
+	ts, err := ar.PendingFunds(lendingclub.ByCancellable(true))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,225 @@
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+)
+
+type valueKind int
+
+const (
+	kindNumber valueKind = iota + 1
+	kindString
+	kindTime
+	kindBool
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case kindNumber:
+		return "number"
+	case kindString:
+		return "string"
+	case kindTime:
+		return "date"
+	case kindBool:
+		return "boolean"
+	}
+
+	return "unknown"
+}
+
+// literal is a value either written in an expression or read from a loan.
+type literal struct {
+	kind valueKind
+	null bool
+	num  decimal.Decimal
+	s    string
+	t    time.Time
+	b    bool
+	text string
+}
+
+func (l literal) String() string {
+	if l.text != "" {
+		return l.text
+	}
+
+	switch {
+	case l.null:
+		return "null"
+	case l.kind == kindNumber:
+		return l.num.String()
+	case l.kind == kindString:
+		return fmt.Sprintf("%q", l.s)
+	case l.kind == kindTime:
+		return l.t.Format("2006-01-02")
+	case l.kind == kindBool:
+		return fmt.Sprint(l.b)
+	}
+
+	return ""
+}
+
+// compare returns -1, 0 or 1 as a is less than, equal to or greater than b.
+// Both must be non-null values of the same kind.
+func compare(a, b literal) int {
+	switch a.kind {
+	case kindNumber:
+		return a.num.Cmp(b.num)
+	case kindString:
+		return strings.Compare(a.s, b.s)
+	case kindTime:
+		switch {
+		case a.t.Before(b.t):
+			return -1
+		case a.t.After(b.t):
+			return 1
+		}
+		return 0
+	case kindBool:
+		if a.b == b.b {
+			return 0
+		}
+		if !a.b {
+			return -1
+		}
+		return 1
+	}
+
+	return 0
+}
+
+func parseNumber(t token) (literal, error) {
+	d, err := decimal.NewFromString(t.text)
+	if err != nil {
+		return literal{}, fmt.Errorf("filter: invalid number %q at %d", t.text, t.pos)
+	}
+
+	return literal{kind: kindNumber, num: d, text: t.text}, nil
+}
+
+var timeLayouts = []string{"2006-01-02", time.RFC3339, "2006-01-02T15:04:05.999-0700"}
+
+// convert turns a literal written in an expression into the kind of f.
+func (f field) convert(lit literal) (literal, error) {
+	if lit.null || lit.kind == f.kind {
+		return lit, nil
+	}
+
+	if f.kind == kindTime && lit.kind == kindString {
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, lit.s); err == nil {
+				lit.kind, lit.t = kindTime, t
+				return lit, nil
+			}
+		}
+		return lit, fmt.Errorf("filter: %s is a date, %s is not", f.name, lit)
+	}
+
+	return lit, fmt.Errorf("filter: %s is a %s, cannot compare with %s %s", f.name, f.kind, lit.kind, lit)
+}
+
+// field is a Loan struct field addressed by its JSON name.
+type field struct {
+	name  string
+	index int
+	kind  valueKind
+	get   func(v reflect.Value) literal
+}
+
+var (
+	decimalType = reflect.TypeOf(decimal.Decimal{})
+	timeType    = reflect.TypeOf(lendingclub.Time{})
+
+	fields = loanFields()
+)
+
+func loanFields() map[string]field {
+	fs := make(map[string]field)
+
+	t := reflect.TypeOf(lendingclub.Loan{})
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		kind, get := accessor(sf.Type)
+		if get == nil {
+			continue
+		}
+
+		fs[name] = field{name: name, index: i, kind: kind, get: get}
+	}
+
+	return fs
+}
+
+// accessor returns how to read a value of type t as a literal. Pointers are
+// read as null when nil.
+func accessor(t reflect.Type) (valueKind, func(reflect.Value) literal) {
+	if t.Kind() == reflect.Ptr {
+		kind, get := accessor(t.Elem())
+		if get == nil {
+			return 0, nil
+		}
+		return kind, func(v reflect.Value) literal {
+			if v.IsNil() {
+				return literal{kind: kind, null: true}
+			}
+			return get(v.Elem())
+		}
+	}
+
+	switch {
+	case t == decimalType:
+		return kindNumber, func(v reflect.Value) literal {
+			return literal{kind: kindNumber, num: v.Interface().(decimal.Decimal)}
+		}
+	case t == timeType:
+		return kindTime, func(v reflect.Value) literal {
+			return literal{kind: kindTime, t: v.Interface().(lendingclub.Time).Time}
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return kindNumber, func(v reflect.Value) literal {
+			return literal{kind: kindNumber, num: decimal.New(v.Int(), 0)}
+		}
+	case reflect.Float32, reflect.Float64:
+		return kindNumber, func(v reflect.Value) literal {
+			return literal{kind: kindNumber, num: decimal.NewFromFloat(v.Float())}
+		}
+	case reflect.String:
+		return kindString, func(v reflect.Value) literal {
+			return literal{kind: kindString, s: v.String()}
+		}
+	case reflect.Bool:
+		return kindBool, func(v reflect.Value) literal {
+			return literal{kind: kindBool, b: v.Bool()}
+		}
+	}
+
+	return 0, nil
+}
+
+func lookupField(name string) (field, error) {
+	f, ok := fields[name]
+	if !ok {
+		return field{}, fmt.Errorf("unknown loan field %q", name)
+	}
+
+	return f, nil
+}
+
+func (f field) value(loan reflect.Value) literal {
+	return f.get(loan.Field(f.index))
+}
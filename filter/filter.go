@@ -0,0 +1,95 @@
+/*
+Package filter screens Lending Club loans with small boolean expressions.
+
+Expressions refer to lendingclub.Loan fields by their JSON names and combine
+comparisons with &&, || and !:
+
+	dti < 20 && grade in ["A", "B"] && empLength >= 2
+	addrState not in ["CA", "NY"] || annualInc > 100000
+	reviewStatusD != null && earliestCrLine < "2005-01-01"
+
+Numbers are compared exactly as decimals, dates can be written as
+"2006-01-02" strings and nullable fields (such as empLength) can be compared
+with null. A comparison against a null field is false.
+
+An expression is compiled once into a Filter, which can then be evaluated
+against any number of loans.
+*/
+package filter
+
+import (
+	"reflect"
+
+	"github.com/Tonkpils/lendingclub"
+)
+
+// Filter is a compiled loan filter expression. It is safe for concurrent use.
+type Filter struct {
+	src  string
+	root node
+}
+
+// Compile parses expr into a Filter.
+func Compile(expr string) (*Filter, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Filter{src: expr, root: root}, nil
+}
+
+// MustCompile is like Compile but panics if the expression cannot be parsed.
+func MustCompile(expr string) *Filter {
+	f, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return f
+}
+
+// String returns the source expression of the filter.
+func (f *Filter) String() string {
+	return f.src
+}
+
+// Match reports whether loan satisfies the filter.
+func (f *Filter) Match(loan *lendingclub.Loan) bool {
+	return f.root.match(reflect.ValueOf(loan).Elem())
+}
+
+// Evaluate reports whether loan satisfies the filter and, if it does not,
+// every condition it failed.
+func (f *Filter) Evaluate(loan *lendingclub.Loan) (bool, []string) {
+	reasons := f.root.explain(reflect.ValueOf(loan).Elem())
+	return len(reasons) == 0, reasons
+}
+
+// Rejection is a loan that did not satisfy a filter, with the reasons why.
+type Rejection struct {
+	Loan    lendingclub.Loan
+	Reasons []string
+}
+
+// Apply splits loans into those that satisfy the filter and those that do
+// not.
+func (f *Filter) Apply(loans []lendingclub.Loan) ([]lendingclub.Loan, []Rejection) {
+	var accepted []lendingclub.Loan
+	var rejected []Rejection
+	for i := range loans {
+		if ok, reasons := f.Evaluate(&loans[i]); ok {
+			accepted = append(accepted, loans[i])
+		} else {
+			rejected = append(rejected, Rejection{Loan: loans[i], Reasons: reasons})
+		}
+	}
+
+	return accepted, rejected
+}
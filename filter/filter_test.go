@@ -0,0 +1,116 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLoans() []lendingclub.Loan {
+	empLength := 5
+	earliest := lendingclub.Time{Time: time.Date(1999, 3, 1, 0, 0, 0, 0, time.UTC)}
+
+	return []lendingclub.Loan{
+		{
+			ID:                   1,
+			Grade:                "A",
+			DebtToIncome:         decimal.NewFromFloat(12.5),
+			EmploymentLength:     &empLength,
+			FICORangeLow:         740,
+			InquiriesLast6Months: 0,
+			Purpose:              "debt_consolidation",
+			AddressState:         "CA",
+			EarliestCreditLine:   &earliest,
+		},
+		{
+			ID:                   2,
+			Grade:                "D",
+			DebtToIncome:         decimal.NewFromFloat(27.9),
+			FICORangeLow:         670,
+			InquiriesLast6Months: 3,
+			Purpose:              "credit_card",
+			AddressState:         "NY",
+		},
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	loans := testLoans()
+
+	cases := []struct {
+		expr string
+		want []bool
+	}{
+		{`dti < 20 && grade in ["A","B"] && empLength >= 2`, []bool{true, false}},
+		{`dti <= 27.9`, []bool{true, true}},
+		{`grade == "D" || ficoRangeLow >= 740`, []bool{true, true}},
+		{`!(grade in ["A"])`, []bool{false, true}},
+		{`addrState not in ["CA", "TX"]`, []bool{false, true}},
+		{`empLength == null`, []bool{false, true}},
+		{`empLength != null and incLast6Mths = 0`, []bool{true, false}},
+		{`earliestCrLine < "2005-01-01"`, []bool{true, false}},
+		{`(purpose == "credit_card" || purpose == "debt_consolidation") && dti > -1`, []bool{true, true}},
+	}
+
+	for _, c := range cases {
+		f, err := Compile(c.expr)
+		require.NoError(t, err, c.expr)
+
+		for i := range loans {
+			assert.Equal(t, c.want[i], f.Match(&loans[i]), "%s on loan %d", c.expr, loans[i].ID)
+		}
+	}
+}
+
+func TestFilterEvaluateReasons(t *testing.T) {
+	loans := testLoans()
+	f := MustCompile(`dti < 20 && grade in ["A","B"] && empLength >= 2 && addrState != "TX"`)
+
+	ok, reasons := f.Evaluate(&loans[0])
+	assert.True(t, ok)
+	assert.Empty(t, reasons)
+
+	ok, reasons = f.Evaluate(&loans[1])
+	assert.False(t, ok)
+	assert.Equal(t, []string{
+		`dti is 27.9, want < 20`,
+		`grade is "D", want grade in ["A", "B"]`,
+		`empLength is null, want >= 2`,
+	}, reasons)
+}
+
+func TestFilterApply(t *testing.T) {
+	f := MustCompile(`ficoRangeLow >= 700`)
+
+	accepted, rejected := f.Apply(testLoans())
+	require.Len(t, accepted, 1)
+	assert.Equal(t, 1, accepted[0].ID)
+	require.Len(t, rejected, 1)
+	assert.Equal(t, 2, rejected[0].Loan.ID)
+	assert.Equal(t, []string{"ficoRangeLow is 670, want >= 700"}, rejected[0].Reasons)
+}
+
+func TestCompileErrors(t *testing.T) {
+	cases := []string{
+		``,
+		`dti <`,
+		`dti < "high"`,
+		`unknownField > 1`,
+		`grade in ["A"`,
+		`dti < 20 &&`,
+		`(dti < 20`,
+		`grade`,
+		`dti > null`,
+		`earliestCrLine < "last year"`,
+		`grade == "A`,
+	}
+
+	for _, expr := range cases {
+		_, err := Compile(expr)
+		assert.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,149 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokComma
+	tokAnd
+	tokOr
+	tokNot
+	tokEq
+	tokNe
+	tokLt
+	tokLe
+	tokGt
+	tokGe
+	tokIn
+)
+
+var tokenNames = map[tokenKind]string{
+	tokEOF:      "end of expression",
+	tokIdent:    "identifier",
+	tokNumber:   "number",
+	tokString:   "string",
+	tokLParen:   "(",
+	tokRParen:   ")",
+	tokLBracket: "[",
+	tokRBracket: "]",
+	tokComma:    ",",
+	tokAnd:      "&&",
+	tokOr:       "||",
+	tokNot:      "!",
+	tokEq:       "==",
+	tokNe:       "!=",
+	tokLt:       "<",
+	tokLe:       "<=",
+	tokGt:       ">",
+	tokGe:       ">=",
+	tokIn:       "in",
+}
+
+var punctuation = map[rune]tokenKind{
+	'(': tokLParen,
+	')': tokRParen,
+	'[': tokLBracket,
+	']': tokRBracket,
+	',': tokComma,
+}
+
+func (k tokenKind) String() string {
+	return tokenNames[k]
+}
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits an expression into tokens.
+func lex(src string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']' || c == ',':
+			tokens = append(tokens, token{kind: punctuation[c], text: string(c), pos: i})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexRune(src[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("filter: unterminated string at %d", i)
+			}
+			tokens = append(tokens, token{kind: tokString, text: src[i+1 : i+1+end], pos: i})
+			i += end + 2
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1]))):
+			j := i + 1
+			for j < len(src) && (unicode.IsDigit(rune(src[j])) || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[i:j], pos: i})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(src) && (unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j])) || src[j] == '_') {
+				j++
+			}
+			word := src[i:j]
+			switch word {
+			case "in":
+				tokens = append(tokens, token{kind: tokIn, text: word, pos: i})
+			case "and":
+				tokens = append(tokens, token{kind: tokAnd, text: word, pos: i})
+			case "or":
+				tokens = append(tokens, token{kind: tokOr, text: word, pos: i})
+			case "not":
+				tokens = append(tokens, token{kind: tokNot, text: word, pos: i})
+			default:
+				tokens = append(tokens, token{kind: tokIdent, text: word, pos: i})
+			}
+			i = j
+		default:
+			op, kind := operator(src[i:])
+			if op == "" {
+				return nil, fmt.Errorf("filter: unexpected %q at %d", c, i)
+			}
+			tokens = append(tokens, token{kind: kind, text: op, pos: i})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+func operator(s string) (string, tokenKind) {
+	// Two-character operators must be tried before their one-character
+	// prefixes.
+	ops := []struct {
+		text string
+		kind tokenKind
+	}{
+		{"&&", tokAnd}, {"||", tokOr}, {"==", tokEq}, {"!=", tokNe},
+		{"<=", tokLe}, {">=", tokGe}, {"<", tokLt}, {">", tokGt},
+		{"!", tokNot}, {"=", tokEq},
+	}
+	for _, op := range ops {
+		if strings.HasPrefix(s, op.text) {
+			return op.text, op.kind
+		}
+	}
+
+	return "", tokEOF
+}
@@ -0,0 +1,239 @@
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// node is a compiled part of an expression. match reports whether a loan
+// satisfies it; explain returns why it does not, or nil if it does.
+type node interface {
+	match(loan reflect.Value) bool
+	explain(loan reflect.Value) []string
+	String() string
+}
+
+type compareNode struct {
+	f   field
+	op  tokenKind
+	lit literal
+}
+
+func newCompareNode(f field, op token, lit literal) (node, error) {
+	lit, err := f.convert(lit)
+	if err != nil {
+		return nil, err
+	}
+
+	if lit.null && op.kind != tokEq && op.kind != tokNe {
+		return nil, fmt.Errorf("filter: cannot use %s with null at %d", op.text, op.pos)
+	}
+	if f.kind == kindBool && op.kind != tokEq && op.kind != tokNe {
+		return nil, fmt.Errorf("filter: cannot use %s with boolean %s at %d", op.text, f.name, op.pos)
+	}
+
+	return &compareNode{f: f, op: op.kind, lit: lit}, nil
+}
+
+func (n *compareNode) match(loan reflect.Value) bool {
+	v := n.f.value(loan)
+	if n.lit.null {
+		return v.null == (n.op == tokEq)
+	}
+	if v.null {
+		return false
+	}
+
+	c := compare(v, n.lit)
+	switch n.op {
+	case tokEq:
+		return c == 0
+	case tokNe:
+		return c != 0
+	case tokLt:
+		return c < 0
+	case tokLe:
+		return c <= 0
+	case tokGt:
+		return c > 0
+	case tokGe:
+		return c >= 0
+	}
+
+	return false
+}
+
+func (n *compareNode) explain(loan reflect.Value) []string {
+	if n.match(loan) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("%s is %s, want %s %s", n.f.name, n.f.value(loan), n.op, n.lit)}
+}
+
+func (n *compareNode) String() string {
+	return fmt.Sprintf("%s %s %s", n.f.name, n.op, n.lit)
+}
+
+type inNode struct {
+	f      field
+	lits   []literal
+	negate bool
+}
+
+func newInNode(f field, lits []literal, negate bool) (node, error) {
+	for i, lit := range lits {
+		lit, err := f.convert(lit)
+		if err != nil {
+			return nil, err
+		}
+		lits[i] = lit
+	}
+
+	return &inNode{f: f, lits: lits, negate: negate}, nil
+}
+
+func (n *inNode) match(loan reflect.Value) bool {
+	v := n.f.value(loan)
+
+	found := false
+	for _, lit := range n.lits {
+		if v.null || lit.null {
+			found = v.null && lit.null
+		} else {
+			found = compare(v, lit) == 0
+		}
+		if found {
+			break
+		}
+	}
+
+	return found != n.negate
+}
+
+func (n *inNode) explain(loan reflect.Value) []string {
+	if n.match(loan) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("%s is %s, want %s", n.f.name, n.f.value(loan), n.String())}
+}
+
+func (n *inNode) String() string {
+	lits := make([]string, len(n.lits))
+	for i, lit := range n.lits {
+		lits[i] = lit.String()
+	}
+
+	op := "in"
+	if n.negate {
+		op = "not in"
+	}
+
+	return fmt.Sprintf("%s %s [%s]", n.f.name, op, strings.Join(lits, ", "))
+}
+
+type andNode struct {
+	children []node
+}
+
+func (n *andNode) match(loan reflect.Value) bool {
+	for _, c := range n.children {
+		if !c.match(loan) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// explain reports every failing condition, not just the first one.
+func (n *andNode) explain(loan reflect.Value) []string {
+	var reasons []string
+	for _, c := range n.children {
+		reasons = append(reasons, c.explain(loan)...)
+	}
+
+	return reasons
+}
+
+func (n *andNode) String() string {
+	return join(n.children, " && ")
+}
+
+type orNode struct {
+	children []node
+}
+
+func (n *orNode) match(loan reflect.Value) bool {
+	for _, c := range n.children {
+		if c.match(loan) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n *orNode) explain(loan reflect.Value) []string {
+	if n.match(loan) {
+		return nil
+	}
+
+	var reasons []string
+	for _, c := range n.children {
+		reasons = append(reasons, c.explain(loan)...)
+	}
+
+	return reasons
+}
+
+func (n *orNode) String() string {
+	return join(n.children, " || ")
+}
+
+type notNode struct {
+	child node
+}
+
+func (n *notNode) match(loan reflect.Value) bool {
+	return !n.child.match(loan)
+}
+
+func (n *notNode) explain(loan reflect.Value) []string {
+	if n.match(loan) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("want %s", n.String())}
+}
+
+func (n *notNode) String() string {
+	return "!" + n.child.String()
+}
+
+type parenNode struct {
+	child node
+}
+
+func (n *parenNode) match(loan reflect.Value) bool {
+	return n.child.match(loan)
+}
+
+func (n *parenNode) explain(loan reflect.Value) []string {
+	return n.child.explain(loan)
+}
+
+func (n *parenNode) String() string {
+	return "(" + n.child.String() + ")"
+}
+
+func join(nodes []node, sep string) string {
+	parts := make([]string, len(nodes))
+	for i, n := range nodes {
+		parts[i] = n.String()
+	}
+
+	return strings.Join(parts, sep)
+}
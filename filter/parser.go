@@ -0,0 +1,215 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parser builds a node tree from tokens using recursive descent:
+//
+//	expr       = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" expr ")" | comparison
+//	comparison = ident [ op literal | [ "not" ] "in" list ]
+//	list       = "[" literal { "," literal } "]"
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) expect(kind tokenKind) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, p.errorf(t, "expected %s", kind)
+	}
+
+	return t, nil
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	found := t.text
+	if t.kind == tokEOF {
+		found = tokEOF.String()
+	}
+
+	return fmt.Errorf("filter: %s, found %q at %d", fmt.Sprintf(format, args...), found, t.pos)
+}
+
+func (p *parser) parse() (node, error) {
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "expected && or ||")
+	}
+
+	return n, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	n, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []node{n}
+	for p.peek().kind == tokOr {
+		p.next()
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+
+	if len(children) == 1 {
+		return children[0], nil
+	}
+
+	return &orNode{children: children}, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	n, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []node{n}
+	for p.peek().kind == tokAnd {
+		p.next()
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+
+	if len(children) == 1 {
+		return children[0], nil
+	}
+
+	return &andNode{children: children}, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	switch p.peek().kind {
+	case tokNot:
+		p.next()
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{child: n}, nil
+	case tokLParen:
+		p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen); err != nil {
+			return nil, err
+		}
+		return &parenNode{child: n}, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	t, err := p.expect(tokIdent)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := lookupField(t.text)
+	if err != nil {
+		return nil, fmt.Errorf("filter: %v at %d", err, t.pos)
+	}
+
+	op := p.peek()
+	switch op.kind {
+	case tokEq, tokNe, tokLt, tokLe, tokGt, tokGe:
+		p.next()
+		lit, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return newCompareNode(f, op, lit)
+	case tokIn:
+		p.next()
+		return p.parseIn(f, false)
+	case tokNot:
+		p.next()
+		if _, err := p.expect(tokIn); err != nil {
+			return nil, err
+		}
+		return p.parseIn(f, true)
+	}
+
+	// A bare boolean field is true when set.
+	if f.kind != kindBool {
+		return nil, p.errorf(op, "expected comparison after %s", f.name)
+	}
+
+	return newCompareNode(f, token{kind: tokEq, text: "=="}, literal{kind: kindBool, b: true, text: "true"})
+}
+
+func (p *parser) parseIn(f field, negate bool) (node, error) {
+	if _, err := p.expect(tokLBracket); err != nil {
+		return nil, err
+	}
+
+	var lits []literal
+	for {
+		lit, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		lits = append(lits, lit)
+
+		t := p.next()
+		if t.kind == tokRBracket {
+			break
+		}
+		if t.kind != tokComma {
+			return nil, p.errorf(t, "expected , or ]")
+		}
+	}
+
+	return newInNode(f, lits, negate)
+}
+
+func (p *parser) parseLiteral() (literal, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return parseNumber(t)
+	case tokString:
+		return literal{kind: kindString, s: t.text, text: fmt.Sprintf("%q", t.text)}, nil
+	case tokIdent:
+		switch strings.ToLower(t.text) {
+		case "true", "false":
+			return literal{kind: kindBool, b: strings.ToLower(t.text) == "true", text: t.text}, nil
+		case "null", "nil":
+			return literal{null: true, text: "null"}, nil
+		}
+	}
+
+	return literal{}, p.errorf(t, "expected a number, string, boolean or null")
+}
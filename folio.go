@@ -0,0 +1,248 @@
+package lendingclub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	folioResourcePath     = "/secondarymarket"
+	folioListingsEndpoint = "/listings"
+	tradesResourcePath    = "/accounts/%d/trades"
+	buyTradesEndpoint     = "/buy"
+	sellTradesEndpoint    = "/sell"
+	cancelTradesEndpoint  = "/cancel"
+)
+
+// FolioResource trades notes on the FOLIO secondary market on behalf of an
+// investor.
+type FolioResource struct {
+	client         *Client
+	endpoint       string
+	tradesEndpoint string
+	investorID     int
+}
+
+func (c *Client) Folio(investorID int) *FolioResource {
+	return &FolioResource{
+		client:         c,
+		endpoint:       c.baseURL + folioResourcePath,
+		tradesEndpoint: fmt.Sprintf(c.baseURL+tradesResourcePath, investorID),
+		investorID:     investorID,
+	}
+}
+
+// FolioListing is a note offered for sale on the secondary market.
+type FolioListing struct {
+	LoanID               int             `json:"loanId"`
+	NoteID               int             `json:"noteId"`
+	OrderID              int             `json:"orderId"`
+	Grade                string          `json:"loanClass"`
+	LoanStatus           LoanStatus      `json:"loanStatus"`
+	InterestRate         decimal.Decimal `json:"interestRate"`
+	LoanMaturity         int             `json:"loanMaturity"`
+	RemainingPayments    int             `json:"remainingPayments"`
+	OriginalNoteAmount   decimal.Decimal `json:"originalNoteAmount"`
+	OutstandingPrincipal decimal.Decimal `json:"outstandingPrincipal"`
+	AccruedInterest      decimal.Decimal `json:"accruedInterest"`
+	AskingPrice          decimal.Decimal `json:"askingPrice"`
+	MarkupDiscount       decimal.Decimal `json:"markupDiscount"`
+	YTM                  decimal.Decimal `json:"ytm"`
+	DaysSinceLastPayment *int            `json:"daysSinceLastPayment"`
+	CreditScoreTrend     string          `json:"creditScoreTrend"`
+	FICOEndRange         string          `json:"ficoEndRange"`
+	NeverLate            bool            `json:"neverLate"`
+	DateListed           Time            `json:"dateListed"`
+}
+
+func (fr *FolioResource) Listings() ([]FolioListing, error) {
+	return fr.ListingsContext(context.Background())
+}
+
+func (fr *FolioResource) ListingsContext(ctx context.Context) ([]FolioListing, error) {
+	req, err := fr.client.newRequest(ctx, "GET", fr.endpoint+folioListingsEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := fr.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var listings struct {
+		Listings []FolioListing `json:"listings"`
+	}
+	err = fr.client.processResponse(res, &listings)
+
+	return listings.Listings, err
+}
+
+// NoteBid is an offer to buy a listed note at BidPrice, which must match the
+// listing's asking price.
+type NoteBid struct {
+	LoanID   int             `json:"loanId"`
+	NoteID   int             `json:"noteId"`
+	OrderID  int             `json:"orderId"`
+	BidPrice decimal.Decimal `json:"bidPrice"`
+}
+
+// Bid returns a NoteBid for the listing at its asking price.
+func (fl FolioListing) Bid() NoteBid {
+	return NoteBid{
+		LoanID:   fl.LoanID,
+		NoteID:   fl.NoteID,
+		OrderID:  fl.OrderID,
+		BidPrice: fl.AskingPrice,
+	}
+}
+
+// NoteSale lists an owned note for sale at AskingPrice.
+type NoteSale struct {
+	Note        Note
+	AskingPrice decimal.Decimal
+}
+
+// TradeConfirmation is the outcome of buying, selling or cancelling the sale
+// of a single note.
+type TradeConfirmation struct {
+	LoanID          int             `json:"loanId"`
+	NoteID          int             `json:"noteId"`
+	OrderID         int             `json:"orderId"`
+	Price           decimal.Decimal `json:"price"`
+	ExecutionStatus string          `json:"executionStatus"`
+}
+
+type TradeResult struct {
+	Status        string              `json:"status"`
+	Confirmations []TradeConfirmation `json:"confirmations"`
+}
+
+type tradeNote struct {
+	LoanID      int64            `json:"loanId"`
+	NoteID      int64            `json:"noteId"`
+	OrderID     int64            `json:"orderId"`
+	AskingPrice *decimal.Decimal `json:"askingPrice,omitempty"`
+}
+
+func newTradeNote(note Note) tradeNote {
+	return tradeNote{
+		LoanID:  note.LoanID.IntPart(),
+		NoteID:  note.ID.IntPart(),
+		OrderID: note.OrderID.IntPart(),
+	}
+}
+
+func tradePreview(notes []tradeNote) []TradeConfirmation {
+	preview := make([]TradeConfirmation, len(notes))
+	for i, n := range notes {
+		preview[i] = TradeConfirmation{LoanID: int(n.LoanID), NoteID: int(n.NoteID), OrderID: int(n.OrderID)}
+		if n.AskingPrice != nil {
+			preview[i].Price = *n.AskingPrice
+		}
+	}
+
+	return preview
+}
+
+func (fr *FolioResource) Buy(bids []NoteBid) (*TradeResult, error) {
+	return fr.BuyContext(context.Background(), bids)
+}
+
+func (fr *FolioResource) BuyContext(ctx context.Context, bids []NoteBid) (*TradeResult, error) {
+	buy := struct {
+		AccountID int       `json:"aid"`
+		Notes     []NoteBid `json:"notes"`
+	}{
+		AccountID: fr.investorID,
+		Notes:     bids,
+	}
+
+	preview := make([]TradeConfirmation, len(bids))
+	for i, bid := range bids {
+		preview[i] = TradeConfirmation{LoanID: bid.LoanID, NoteID: bid.NoteID, OrderID: bid.OrderID, Price: bid.BidPrice}
+	}
+
+	return fr.trade(ctx, buyTradesEndpoint, buy, preview)
+}
+
+// Sell lists the given owned notes for sale until expireDate. A nil
+// expireDate uses Lending Club's default listing period.
+func (fr *FolioResource) Sell(expireDate *Time, sales []NoteSale) (*TradeResult, error) {
+	return fr.SellContext(context.Background(), expireDate, sales)
+}
+
+func (fr *FolioResource) SellContext(ctx context.Context, expireDate *Time, sales []NoteSale) (*TradeResult, error) {
+	notes := make([]tradeNote, len(sales))
+	for i, sale := range sales {
+		notes[i] = newTradeNote(sale.Note)
+		notes[i].AskingPrice = &sales[i].AskingPrice
+	}
+
+	sell := struct {
+		AccountID  int         `json:"aid"`
+		ExpireDate *Time       `json:"expireDate,omitempty"`
+		Notes      []tradeNote `json:"notes"`
+	}{
+		AccountID:  fr.investorID,
+		ExpireDate: expireDate,
+		Notes:      notes,
+	}
+
+	return fr.trade(ctx, sellTradesEndpoint, sell, tradePreview(notes))
+}
+
+// CancelSales withdraws the given notes from sale.
+func (fr *FolioResource) CancelSales(notes []Note) (*TradeResult, error) {
+	return fr.CancelSalesContext(context.Background(), notes)
+}
+
+func (fr *FolioResource) CancelSalesContext(ctx context.Context, notes []Note) (*TradeResult, error) {
+	refs := make([]tradeNote, len(notes))
+	for i, note := range notes {
+		refs[i] = newTradeNote(note)
+	}
+
+	cancel := struct {
+		AccountID int         `json:"aid"`
+		Notes     []tradeNote `json:"notes"`
+	}{
+		AccountID: fr.investorID,
+		Notes:     refs,
+	}
+
+	return fr.trade(ctx, cancelTradesEndpoint, cancel, tradePreview(refs))
+}
+
+// trade sends a trade request. preview is the result reported in dry-run
+// mode.
+func (fr *FolioResource) trade(ctx context.Context, endpoint string, body interface{}, preview []TradeConfirmation) (*TradeResult, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if fr.client.dryRun {
+		fr.client.logDryRun("POST", fr.tradesEndpoint+endpoint, payload)
+		return simulateTrade(preview)
+	}
+
+	req, err := fr.client.newRequest(ctx, "POST", fr.tradesEndpoint+endpoint, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := fr.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result TradeResult
+	err = fr.client.processResponse(res, &result)
+
+	return &result, err
+}
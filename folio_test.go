@@ -0,0 +1,239 @@
+package lendingclub
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// folioFake is an in-memory FOLIO market. Bought notes leave the market,
+// sold notes join it and cancelled sales leave it again.
+type folioFake struct {
+	t        *testing.T
+	mu       sync.Mutex
+	listings map[int]FolioListing
+}
+
+func newFolioFake(t *testing.T) *httptest.Server {
+	f, err := os.Open(filepath.Join("./fixtures", "folio_listings.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var seed struct {
+		Listings []FolioListing `json:"listings"`
+	}
+	require.NoError(t, json.NewDecoder(f).Decode(&seed))
+
+	fake := &folioFake{t: t, listings: make(map[int]FolioListing)}
+	for _, l := range seed.Listings {
+		fake.listings[l.NoteID] = l
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/secondarymarket/listings", fake.list)
+	mux.HandleFunc(fmt.Sprintf("/accounts/%d/trades/buy", TestAccountID), fake.buy)
+	mux.HandleFunc(fmt.Sprintf("/accounts/%d/trades/sell", TestAccountID), fake.sell)
+	mux.HandleFunc(fmt.Sprintf("/accounts/%d/trades/cancel", TestAccountID), fake.cancel)
+
+	return httptest.NewServer(mux)
+}
+
+type fakeTrade struct {
+	AccountID int `json:"aid"`
+	Notes     []struct {
+		LoanID      int             `json:"loanId"`
+		NoteID      int             `json:"noteId"`
+		OrderID     int             `json:"orderId"`
+		BidPrice    decimal.Decimal `json:"bidPrice"`
+		AskingPrice decimal.Decimal `json:"askingPrice"`
+	} `json:"notes"`
+}
+
+func (f *folioFake) decode(req *http.Request) fakeTrade {
+	defer req.Body.Close()
+	assert.Equal(f.t, "POST", req.Method)
+
+	var trade fakeTrade
+	require.NoError(f.t, json.NewDecoder(req.Body).Decode(&trade))
+	assert.Equal(f.t, TestAccountID, trade.AccountID)
+
+	return trade
+}
+
+func (f *folioFake) list(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	listings := make([]FolioListing, 0, len(f.listings))
+	for _, l := range f.listings {
+		listings = append(listings, l)
+	}
+	sort.Slice(listings, func(i, j int) bool { return listings[i].NoteID < listings[j].NoteID })
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"listings": listings})
+}
+
+func (f *folioFake) buy(w http.ResponseWriter, req *http.Request) {
+	trade := f.decode(req)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result TradeResult
+	for _, n := range trade.Notes {
+		tc := TradeConfirmation{LoanID: n.LoanID, NoteID: n.NoteID, OrderID: n.OrderID, Price: n.BidPrice}
+		l, ok := f.listings[n.NoteID]
+		switch {
+		case !ok:
+			tc.ExecutionStatus = "NOTE_NOT_AVAILABLE"
+		case !l.AskingPrice.Equals(n.BidPrice):
+			tc.ExecutionStatus = "BID_PRICE_MISMATCH"
+		default:
+			delete(f.listings, n.NoteID)
+			tc.ExecutionStatus = "SUCCESS"
+		}
+		result.Confirmations = append(result.Confirmations, tc)
+	}
+	result.Status = "SUCCESS"
+
+	json.NewEncoder(w).Encode(result)
+}
+
+func (f *folioFake) sell(w http.ResponseWriter, req *http.Request) {
+	trade := f.decode(req)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result TradeResult
+	for _, n := range trade.Notes {
+		f.listings[n.NoteID] = FolioListing{
+			LoanID:      n.LoanID,
+			NoteID:      n.NoteID,
+			OrderID:     n.OrderID,
+			AskingPrice: n.AskingPrice,
+		}
+		result.Confirmations = append(result.Confirmations, TradeConfirmation{
+			LoanID:          n.LoanID,
+			NoteID:          n.NoteID,
+			OrderID:         n.OrderID,
+			Price:           n.AskingPrice,
+			ExecutionStatus: "SUCCESS",
+		})
+	}
+	result.Status = "SUCCESS"
+
+	json.NewEncoder(w).Encode(result)
+}
+
+func (f *folioFake) cancel(w http.ResponseWriter, req *http.Request) {
+	trade := f.decode(req)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result TradeResult
+	for _, n := range trade.Notes {
+		tc := TradeConfirmation{LoanID: n.LoanID, NoteID: n.NoteID, OrderID: n.OrderID}
+		if _, ok := f.listings[n.NoteID]; ok {
+			delete(f.listings, n.NoteID)
+			tc.ExecutionStatus = "SUCCESS"
+		} else {
+			tc.ExecutionStatus = "NOTE_NOT_LISTED"
+		}
+		result.Confirmations = append(result.Confirmations, tc)
+	}
+	result.Status = "SUCCESS"
+
+	json.NewEncoder(w).Encode(result)
+}
+
+func TestFolioListings(t *testing.T) {
+	ts := newFolioFake(t)
+	defer ts.Close()
+
+	fr := newClient(ts.URL, "Token", nil).Folio(TestAccountID)
+	listings, err := fr.Listings()
+	require.NoError(t, err)
+	require.Len(t, listings, 2)
+
+	l := listings[0]
+	assert.Equal(t, 22222, l.NoteID)
+	assert.Equal(t, "B3", l.Grade)
+	assert.Equal(t, "17.1", l.AskingPrice.String())
+	assert.Equal(t, "-2.28", l.MarkupDiscount.String())
+	assert.Equal(t, "13.05", l.YTM.String())
+	require.NotNil(t, l.DaysSinceLastPayment)
+	assert.Equal(t, 12, *l.DaysSinceLastPayment)
+	assert.Equal(t, "UP", l.CreditScoreTrend)
+	assert.True(t, l.NeverLate)
+
+	assert.Nil(t, listings[1].DaysSinceLastPayment)
+	assert.Equal(t, "DOWN", listings[1].CreditScoreTrend)
+}
+
+func TestFolioBuy(t *testing.T) {
+	ts := newFolioFake(t)
+	defer ts.Close()
+
+	fr := newClient(ts.URL, "Token", nil).Folio(TestAccountID)
+	listings, err := fr.Listings()
+	require.NoError(t, err)
+
+	lowball := listings[1].Bid()
+	lowball.BidPrice = decimal.NewFromFloat(10)
+
+	result, err := fr.Buy([]NoteBid{listings[0].Bid(), lowball})
+	require.NoError(t, err)
+	require.Len(t, result.Confirmations, 2)
+	assert.Equal(t, "SUCCESS", result.Confirmations[0].ExecutionStatus)
+	assert.Equal(t, "BID_PRICE_MISMATCH", result.Confirmations[1].ExecutionStatus)
+
+	listings, err = fr.Listings()
+	require.NoError(t, err)
+	require.Len(t, listings, 1)
+	assert.Equal(t, 22224, listings[0].NoteID)
+}
+
+func TestFolioSellAndCancel(t *testing.T) {
+	ts := newFolioFake(t)
+	defer ts.Close()
+
+	note := Note{
+		ID:      decimal.New(55555, 0),
+		LoanID:  decimal.New(66666, 0),
+		OrderID: decimal.New(77777, 0),
+	}
+
+	fr := newClient(ts.URL, "Token", nil).Folio(TestAccountID)
+	result, err := fr.Sell(nil, []NoteSale{{Note: note, AskingPrice: decimal.NewFromFloat(24.5)}})
+	require.NoError(t, err)
+	require.Len(t, result.Confirmations, 1)
+	assert.Equal(t, 55555, result.Confirmations[0].NoteID)
+	assert.Equal(t, "SUCCESS", result.Confirmations[0].ExecutionStatus)
+
+	listings, err := fr.Listings()
+	require.NoError(t, err)
+	require.Len(t, listings, 3)
+	assert.Equal(t, "24.5", listings[2].AskingPrice.String())
+
+	result, err = fr.CancelSales([]Note{note, note})
+	require.NoError(t, err)
+	require.Len(t, result.Confirmations, 2)
+	assert.Equal(t, "SUCCESS", result.Confirmations[0].ExecutionStatus)
+	assert.Equal(t, "NOTE_NOT_LISTED", result.Confirmations[1].ExecutionStatus)
+
+	listings, err = fr.Listings()
+	require.NoError(t, err)
+	assert.Len(t, listings, 2)
+}
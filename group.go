@@ -0,0 +1,285 @@
+package lendingclub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// AccountError is the error returned for a single account of a group.
+type AccountError struct {
+	InvestorID int
+	Err        error
+}
+
+func (e *AccountError) Error() string {
+	return fmt.Sprintf("investor %d: %v", e.InvestorID, e.Err)
+}
+
+func (e *AccountError) Unwrap() error {
+	return e.Err
+}
+
+// GroupError collects the errors of the accounts that failed in a group
+// call. The results of the other accounts are still returned alongside it.
+// errors.Is matches it against any of the underlying errors.
+type GroupError []*AccountError
+
+func (e GroupError) Error() string {
+	errs := make([]string, len(e))
+	for i, ae := range e {
+		errs[i] = ae.Error()
+	}
+
+	return "lendingclub: " + strings.Join(errs, "; ")
+}
+
+func (e GroupError) Is(target error) bool {
+	for _, ae := range e {
+		if errors.Is(ae.Err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// defaultGroupConcurrency is how many accounts a group calls at once unless
+// MaxConcurrency says otherwise.
+const defaultGroupConcurrency = 4
+
+// AccountGroup manages several investor accounts under one API key. Calls
+// fan out to the accounts concurrently; the client's rate limiter still
+// paces the requests.
+type AccountGroup struct {
+	// MaxConcurrency caps how many accounts are called at once. It defaults
+	// to 4.
+	MaxConcurrency int
+
+	ids      []int
+	accounts map[int]*AccountsResource
+}
+
+func (c *Client) AccountGroup(investorIDs ...int) *AccountGroup {
+	g := &AccountGroup{accounts: make(map[int]*AccountsResource, len(investorIDs))}
+	for _, id := range investorIDs {
+		if _, ok := g.accounts[id]; ok {
+			continue
+		}
+		g.ids = append(g.ids, id)
+		g.accounts[id] = c.Accounts(id)
+	}
+	sort.Ints(g.ids)
+
+	return g
+}
+
+// InvestorIDs returns the investors in the group in ascending order.
+func (g *AccountGroup) InvestorIDs() []int {
+	return append([]int(nil), g.ids...)
+}
+
+// Account returns the resource of a single investor in the group, or nil.
+func (g *AccountGroup) Account(investorID int) *AccountsResource {
+	return g.accounts[investorID]
+}
+
+// each calls fn for every investor in ids, at most MaxConcurrency at a time,
+// and collects the errors.
+func (g *AccountGroup) each(ids []int, fn func(ar *AccountsResource) error) error {
+	limit := g.MaxConcurrency
+	if limit <= 0 {
+		limit = defaultGroupConcurrency
+	}
+
+	// Investors outside the group fail up front, before any goroutine can
+	// append to errs.
+	var (
+		errs     GroupError
+		accounts []*AccountsResource
+	)
+	for _, id := range ids {
+		ar, ok := g.accounts[id]
+		if !ok {
+			errs = append(errs, &AccountError{InvestorID: id, Err: invalid("investor %d is not in the group", id)})
+			continue
+		}
+		accounts = append(accounts, ar)
+	}
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		sem = make(chan struct{}, limit)
+	)
+	for _, ar := range accounts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(ar *AccountsResource) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(ar); err != nil {
+				mu.Lock()
+				errs = append(errs, &AccountError{InvestorID: ar.investorID, Err: err})
+				mu.Unlock()
+			}
+		}(ar)
+	}
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].InvestorID < errs[j].InvestorID })
+	return errs
+}
+
+// GroupSummary holds the summary of each account and their sum. Total has no
+// InvestorID.
+type GroupSummary struct {
+	Accounts map[int]*Summary
+	Total    Summary
+}
+
+func (g *AccountGroup) Summary() (*GroupSummary, error) {
+	return g.SummaryContext(context.Background())
+}
+
+func (g *AccountGroup) SummaryContext(ctx context.Context) (*GroupSummary, error) {
+	var mu sync.Mutex
+	gs := &GroupSummary{Accounts: make(map[int]*Summary, len(g.ids))}
+
+	err := g.each(g.ids, func(ar *AccountsResource) error {
+		sum, err := ar.SummaryContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		gs.Accounts[ar.investorID] = sum
+		mu.Unlock()
+		return nil
+	})
+
+	t := Summary{
+		AvailableCash:        decimal.Zero,
+		AccruedInterest:      decimal.Zero,
+		OutstandingPrincipal: decimal.Zero,
+		AccountTotal:         decimal.Zero,
+		InFundingBalance:     decimal.Zero,
+		ReceivedInterest:     decimal.Zero,
+		ReceivedPrincipal:    decimal.Zero,
+		ReceivedLateFees:     decimal.Zero,
+	}
+	for _, s := range gs.Accounts {
+		t.AvailableCash = t.AvailableCash.Add(s.AvailableCash)
+		t.AccruedInterest = t.AccruedInterest.Add(s.AccruedInterest)
+		t.OutstandingPrincipal = t.OutstandingPrincipal.Add(s.OutstandingPrincipal)
+		t.AccountTotal = t.AccountTotal.Add(s.AccountTotal)
+		t.TotalNotes += s.TotalNotes
+		t.TotalPortfolios += s.TotalPortfolios
+		t.InFundingBalance = t.InFundingBalance.Add(s.InFundingBalance)
+		t.ReceivedInterest = t.ReceivedInterest.Add(s.ReceivedInterest)
+		t.ReceivedPrincipal = t.ReceivedPrincipal.Add(s.ReceivedPrincipal)
+		t.ReceivedLateFees = t.ReceivedLateFees.Add(s.ReceivedLateFees)
+	}
+	gs.Total = t
+
+	return gs, err
+}
+
+// GroupCash holds the available cash of each account and their sum.
+type GroupCash struct {
+	Accounts map[int]decimal.Decimal
+	Total    decimal.Decimal
+}
+
+func (g *AccountGroup) AvailableCash() (*GroupCash, error) {
+	return g.AvailableCashContext(context.Background())
+}
+
+func (g *AccountGroup) AvailableCashContext(ctx context.Context) (*GroupCash, error) {
+	var mu sync.Mutex
+	gc := &GroupCash{Accounts: make(map[int]decimal.Decimal, len(g.ids)), Total: decimal.Zero}
+
+	err := g.each(g.ids, func(ar *AccountsResource) error {
+		ac, err := ar.AvailableCashContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		gc.Accounts[ar.investorID] = ac.AvailableCash
+		gc.Total = gc.Total.Add(ac.AvailableCash)
+		mu.Unlock()
+		return nil
+	})
+
+	return gc, err
+}
+
+// Notes returns the notes of every account, keyed by investor ID.
+func (g *AccountGroup) Notes() (map[int][]Note, error) {
+	return g.NotesContext(context.Background())
+}
+
+func (g *AccountGroup) NotesContext(ctx context.Context) (map[int][]Note, error) {
+	var mu sync.Mutex
+	notes := make(map[int][]Note, len(g.ids))
+
+	err := g.each(g.ids, func(ar *AccountsResource) error {
+		n, err := ar.NotesContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		notes[ar.investorID] = n
+		mu.Unlock()
+		return nil
+	})
+
+	return notes, err
+}
+
+// SubmitOrders routes orders, keyed by investor ID, to their accounts and
+// returns the instruction of each account that submitted successfully.
+// Orders for investors outside the group fail without being sent.
+func (g *AccountGroup) SubmitOrders(orders map[int][]OrderSubmission) (map[int]*OrderInstruct, error) {
+	return g.SubmitOrdersContext(context.Background(), orders)
+}
+
+func (g *AccountGroup) SubmitOrdersContext(ctx context.Context, orders map[int][]OrderSubmission) (map[int]*OrderInstruct, error) {
+	ids := make([]int, 0, len(orders))
+	for id, o := range orders {
+		if len(o) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	var mu sync.Mutex
+	instructs := make(map[int]*OrderInstruct, len(ids))
+
+	err := g.each(ids, func(ar *AccountsResource) error {
+		instruct, err := ar.SubmitOrderContext(ctx, orders[ar.investorID])
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		instructs[ar.investorID] = instruct
+		mu.Unlock()
+		return nil
+	})
+
+	return instructs, err
+}
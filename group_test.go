@@ -0,0 +1,172 @@
+package lendingclub
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGroupServer(t *testing.T, cash map[int]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer req.Body.Close()
+
+		var id int
+		var resource string
+		if _, err := fmt.Sscanf(req.URL.Path, "/accounts/%d/%s", &id, &resource); err != nil {
+			t.Errorf("unexpected request %s", req.RequestURI)
+			return
+		}
+
+		c, ok := cash[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch resource {
+		case "summary":
+			fmt.Fprintf(w, `{"investorId": %d, "availableCash": %s, "accountTotal": 100, "totalNotes": 2, "totalPortfolios": 1}`, id, c)
+		case "availablecash":
+			fmt.Fprintf(w, `{"investorId": %d, "availableCash": %s}`, id, c)
+		case "notes":
+			fmt.Fprintf(w, `{"myNotes": [{"loanId": %d, "noteId": %d}]}`, id*10, id*100)
+		case "orders":
+			var body struct {
+				Orders    []OrderSubmission `json:"orders"`
+				AccountID int               `json:"aid"`
+			}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			assert.Equal(t, id, body.AccountID)
+			fmt.Fprintf(w, `{"orderInstructId": %d, "orderConfirmations": [
+				{"loanId": %d, "requestedAmount": 25, "investedAmount": 25, "executionStatus": ["ORDER_FULFILLED"]}
+			]}`, id, body.Orders[0].LoanID)
+		default:
+			t.Errorf("unexpected request %s", req.RequestURI)
+		}
+	}))
+}
+
+func TestAccountGroup(t *testing.T) {
+	ts := newGroupServer(t, map[int]string{1: "50.25", 2: "100"})
+	defer ts.Close()
+
+	g := newClient(ts.URL, "Token", nil).AccountGroup(2, 1, 2)
+	assert.Equal(t, []int{1, 2}, g.InvestorIDs())
+	assert.Nil(t, g.Account(3))
+
+	sum, err := g.Summary()
+	require.NoError(t, err)
+	require.Len(t, sum.Accounts, 2)
+	assert.Equal(t, 2, sum.Accounts[2].InvestorID)
+	assert.Equal(t, "150.25", sum.Total.AvailableCash.String())
+	assert.Equal(t, "200", sum.Total.AccountTotal.String())
+	assert.Equal(t, 4, sum.Total.TotalNotes)
+
+	cash, err := g.AvailableCash()
+	require.NoError(t, err)
+	assert.Equal(t, "50.25", cash.Accounts[1].String())
+	assert.Equal(t, "150.25", cash.Total.String())
+
+	notes, err := g.Notes()
+	require.NoError(t, err)
+	require.Len(t, notes[2], 1)
+	assert.Equal(t, "20", notes[2][0].LoanID.String())
+}
+
+func TestAccountGroupPartialFailure(t *testing.T) {
+	ts := newGroupServer(t, map[int]string{1: "50"})
+	defer ts.Close()
+
+	g := newClient(ts.URL, "Token", nil).AccountGroup(1, 3)
+
+	cash, err := g.AvailableCash()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.Equal(t, "50", cash.Total.String())
+
+	var gerr GroupError
+	require.True(t, errors.As(err, &gerr))
+	require.Len(t, gerr, 1)
+	assert.Equal(t, 3, gerr[0].InvestorID)
+}
+
+func TestAccountGroupSubmitOrders(t *testing.T) {
+	ts := newGroupServer(t, map[int]string{1: "50", 2: "50"})
+	defer ts.Close()
+
+	g := newClient(ts.URL, "Token", nil).AccountGroup(1, 2)
+
+	instructs, err := g.SubmitOrders(map[int][]OrderSubmission{
+		1: {{LoanID: 11, Amount: decimal.New(25, 0)}},
+		2: {{LoanID: 22, Amount: decimal.New(25, 0)}},
+		9: {{LoanID: 99, Amount: decimal.New(25, 0)}},
+	})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.Contains(t, err.Error(), "investor 9")
+
+	require.Len(t, instructs, 2)
+	assert.Equal(t, 11, instructs[1].OrderConfirmations[0].LoanID)
+	assert.Equal(t, 22, instructs[2].OrderConfirmations[0].LoanID)
+}
+
+func TestAccountGroupSubmitOrdersMixedFailures(t *testing.T) {
+	ts := newGroupServer(t, map[int]string{1: "50"})
+	defer ts.Close()
+
+	// Investor 3 is in the group but fails; 9 and 10 are not in it.
+	g := newClient(ts.URL, "Token", nil).AccountGroup(1, 3)
+
+	instructs, err := g.SubmitOrders(map[int][]OrderSubmission{
+		1:  {{LoanID: 11, Amount: decimal.New(25, 0)}},
+		3:  {{LoanID: 33, Amount: decimal.New(25, 0)}},
+		9:  {{LoanID: 99, Amount: decimal.New(25, 0)}},
+		10: {{LoanID: 100, Amount: decimal.New(25, 0)}},
+	})
+	require.Len(t, instructs, 1)
+	assert.Equal(t, 11, instructs[1].OrderConfirmations[0].LoanID)
+
+	var gerr GroupError
+	require.True(t, errors.As(err, &gerr))
+	require.Len(t, gerr, 3)
+	assert.Equal(t, 3, gerr[0].InvestorID)
+	assert.True(t, errors.Is(gerr[0], ErrNotFound))
+	assert.Equal(t, 9, gerr[1].InvestorID)
+	assert.Equal(t, 10, gerr[2].InvestorID)
+	assert.True(t, errors.Is(gerr[2], ErrValidation))
+}
+
+func TestAccountGroupConcurrency(t *testing.T) {
+	var inFlight, peak int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprint(w, `{"availableCash": 10}`)
+	}))
+	defer ts.Close()
+
+	g := newClient(ts.URL, "Token", nil).AccountGroup(1, 2, 3, 4, 5, 6)
+	g.MaxConcurrency = 2
+
+	gc, err := g.AvailableCash()
+	require.NoError(t, err)
+	assert.Equal(t, "60", gc.Total.String())
+	assert.True(t, atomic.LoadInt32(&peak) <= 2)
+}
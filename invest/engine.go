@@ -0,0 +1,329 @@
+/*
+Package invest automates investing in Lending Club loans.
+
+An Engine fetches the listed loans, screens them with a filter, sizes an order
+for each remaining loan against the available cash and the configured caps,
+and submits the orders in batches. Every loan it invests in is recorded in a
+Store so later runs never buy into it again. Limits keep the orders within
+concentration caps per loan, state, grade and purpose.
+*/
+package invest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/Tonkpils/lendingclub/filter"
+	"github.com/shopspring/decimal"
+)
+
+const defaultBatchSize = 100
+
+// UnconfirmedStatus is the execution status stored for orders whose outcome
+// is unknown because submitting them failed after reaching Lending Club.
+const UnconfirmedStatus = "UNCONFIRMED"
+
+// Engine invests in listed loans matching its Filter.
+type Engine struct {
+	Accounts *lendingclub.AccountsResource
+	Loans    *lendingclub.LoansResource
+
+	// Filter selects the loans to invest in. A nil Filter accepts every loan.
+	Filter *filter.Filter
+
+	// ListedOptions are used to fetch the listed loans.
+	ListedOptions *lendingclub.ListedOptions
+
+	// AmountPerLoan is invested in each selected loan. It is rounded down to
+	// a multiple of lendingclub.NoteIncrement and defaults to it.
+	AmountPerLoan decimal.Decimal
+
+	// PortfolioID, if set, is the portfolio new notes are placed in.
+	PortfolioID int
+
+	// MaxPerRun and MaxPerDay cap the amount invested in a single run and
+	// in a calendar day (in Location). Zero means no cap.
+	MaxPerRun decimal.Decimal
+	MaxPerDay decimal.Decimal
+	Location  *time.Location
+
+	// BatchSize is the maximum number of orders per SubmitOrder call.
+	BatchSize int
+
+	// PartialFills, if set, resubmits the unfilled part of partially filled
+	// orders.
+	PartialFills *lendingclub.PartialFillPolicy
+
+	// Limits, if set, trims or rejects orders that would concentrate the
+	// account in a loan, state, grade or purpose.
+	Limits *Limits
+
+	// DryRun builds the orders without submitting or recording them.
+	DryRun bool
+
+	// Store records purchases across runs. It is required. Orders whose
+	// submission failed in a way that may still have placed them are
+	// stored with UnconfirmedStatus.
+	Store Store
+
+	now func() time.Time
+}
+
+// Skip is a loan that passed the filter but was not ordered.
+type Skip struct {
+	Loan   lendingclub.Loan
+	Reason string
+}
+
+// Report describes what a single run did.
+type Report struct {
+	DryRun        bool
+	Listed        int
+	Rejected      []filter.Rejection
+	Skipped       []Skip
+	Orders        []lendingclub.OrderSubmission
+	Confirmations []lendingclub.OrderConfirmation
+	Adjustments   []Adjustment
+	Totals        lendingclub.OrderTotals
+	Purchases     []Purchase
+	Invested      decimal.Decimal
+}
+
+// Run fetches the listed loans once and invests in those that match. The
+// orders are checked with lendingclub.OrderBuilder before anything is
+// submitted; if any is invalid, the report is returned with its
+// lendingclub.OrderErrors.
+func (e *Engine) Run(ctx context.Context) (*Report, error) {
+	if e.Store == nil {
+		return nil, errors.New("invest: engine has no store")
+	}
+
+	listed, err := e.Loans.ListedWithOptionsContext(ctx, e.ListedOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{DryRun: e.DryRun, Listed: len(listed.Loans), Invested: decimal.Zero}
+
+	accepted := listed.Loans
+	if e.Filter != nil {
+		accepted, report.Rejected = e.Filter.Apply(listed.Loans)
+	}
+	if len(accepted) == 0 {
+		return report, nil
+	}
+
+	ac, err := e.Accounts.AvailableCashContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	budget, err := e.budget(ac.AvailableCash)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := e.amountPerLoan()
+	for _, loan := range accepted {
+		owned, err := e.Store.Purchased(loan.ID)
+		if err != nil {
+			return nil, err
+		}
+		if owned {
+			report.Skipped = append(report.Skipped, Skip{Loan: loan, Reason: "already invested"})
+			continue
+		}
+
+		size := amount
+		if remaining := lendingclub.FloorIncrement(loan.LoanAmount.Sub(loan.FundedAmount)); remaining.LessThan(size) {
+			size = remaining
+		}
+		if size.Sign() <= 0 {
+			report.Skipped = append(report.Skipped, Skip{Loan: loan, Reason: "fully funded"})
+			continue
+		}
+		if budget.LessThan(size) {
+			report.Skipped = append(report.Skipped, Skip{Loan: loan, Reason: "budget exhausted"})
+			continue
+		}
+
+		budget = budget.Sub(size)
+		report.Orders = append(report.Orders, lendingclub.OrderSubmission{
+			LoanID:      loan.ID,
+			Amount:      size,
+			PortfolioID: e.PortfolioID,
+		})
+	}
+
+	if e.Limits != nil && len(report.Orders) > 0 {
+		report.Orders, report.Adjustments, err = e.Limits.CheckAccount(ctx, e.Accounts, listed.Loans, report.Orders)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if len(report.Orders) > 0 {
+		if err := lendingclub.NewOrderBuilder(listed.Loans, ac.AvailableCash).Validate(report.Orders); err != nil {
+			return report, err
+		}
+	}
+
+	if e.DryRun {
+		return report, nil
+	}
+
+	return report, e.submit(ctx, report)
+}
+
+func (e *Engine) submit(ctx context.Context, report *Report) error {
+	batchSize := e.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	for start := 0; start < len(report.Orders); start += batchSize {
+		end := start + batchSize
+		if end > len(report.Orders) {
+			end = len(report.Orders)
+		}
+
+		batch := report.Orders[start:end]
+		instruct, err := e.Accounts.SubmitOrderContext(ctx, batch)
+		if err != nil {
+			if placed(err) {
+				if err := e.recordUnconfirmed(batch); err != nil {
+					return err
+				}
+			}
+			return err
+		}
+		if err := e.record(report, instruct); err != nil {
+			return err
+		}
+
+		if e.PartialFills == nil {
+			continue
+		}
+		resubmitted, err := e.PartialFills.Resubmit(ctx, e.Accounts, batch, instruct)
+		for _, instruct := range resubmitted {
+			if err := e.record(report, instruct); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	report.Totals = lendingclub.TotalOrders(report.Confirmations)
+
+	return nil
+}
+
+// record adds the confirmations of instruct to the report and stores every
+// loan money was invested in. Like OrderConfirmation.Outcome, it goes by the
+// invested amount alone, since partial fills do not always carry
+// ORDER_FULFILLED.
+func (e *Engine) record(report *Report, instruct *lendingclub.OrderInstruct) error {
+	for _, oc := range instruct.OrderConfirmations {
+		report.Confirmations = append(report.Confirmations, oc)
+
+		if oc.InvestedAmount.Sign() <= 0 {
+			continue
+		}
+
+		p := Purchase{
+			LoanID:          oc.LoanID,
+			OrderID:         instruct.ID,
+			Amount:          oc.InvestedAmount,
+			ExecutionStatus: oc.ExecutionStatus.String(),
+			Time:            e.clock(),
+		}
+		if err := e.Store.Record(p); err != nil {
+			return err
+		}
+		report.Purchases = append(report.Purchases, p)
+		report.Invested = report.Invested.Add(oc.InvestedAmount)
+	}
+
+	return nil
+}
+
+// recordUnconfirmed stores every order of batch at its full amount so later
+// runs neither buy into those loans again nor overspend the daily cap.
+func (e *Engine) recordUnconfirmed(batch []lendingclub.OrderSubmission) error {
+	for _, o := range batch {
+		p := Purchase{
+			LoanID:          o.LoanID,
+			Amount:          o.Amount,
+			ExecutionStatus: UnconfirmedStatus,
+			Time:            e.clock(),
+		}
+		if err := e.Store.Record(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// placed reports whether an order may have been placed despite err. Only a
+// 4xx response guarantees that Lending Club rejected the whole request.
+func placed(err error) bool {
+	var er *lendingclub.ErrorResponse
+	if errors.As(err, &er) {
+		return er.StatusCode >= http.StatusInternalServerError
+	}
+
+	return !errors.Is(err, lendingclub.ErrValidation)
+}
+
+// budget returns how much of cash the run may invest.
+func (e *Engine) budget(cash decimal.Decimal) (decimal.Decimal, error) {
+	budget := cash
+	if e.MaxPerRun.Sign() > 0 && e.MaxPerRun.LessThan(budget) {
+		budget = e.MaxPerRun
+	}
+
+	if e.MaxPerDay.Sign() > 0 {
+		spent, err := e.Store.SpentSince(e.startOfDay())
+		if err != nil {
+			return decimal.Zero, err
+		}
+		if left := e.MaxPerDay.Sub(spent); left.LessThan(budget) {
+			budget = left
+		}
+	}
+
+	return budget, nil
+}
+
+func (e *Engine) amountPerLoan() decimal.Decimal {
+	amount := lendingclub.FloorIncrement(e.AmountPerLoan)
+	if amount.Sign() <= 0 {
+		return lendingclub.NoteIncrement
+	}
+
+	return amount
+}
+
+func (e *Engine) clock() time.Time {
+	if e.now != nil {
+		return e.now()
+	}
+
+	return time.Now()
+}
+
+func (e *Engine) startOfDay() time.Time {
+	loc := e.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	now := e.clock().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+}
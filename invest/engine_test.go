@@ -0,0 +1,285 @@
+package invest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/Tonkpils/lendingclub/filter"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testInvestorID = 1234
+
+type fakeAPI struct {
+	t      *testing.T
+	mu     sync.Mutex
+	cash   decimal.Decimal
+	orders [][]lendingclub.OrderSubmission
+
+	// failWith, if set, is the status returned after recording the orders.
+	failWith int
+
+	// partial, if set, fills only 25 of each order and reports
+	// LOAN_AMNT_EXCEEDED without ORDER_FULFILLED.
+	partial bool
+}
+
+func newFakeAPI(t *testing.T, cash float64) (*fakeAPI, *httptest.Server) {
+	api := &fakeAPI{t: t, cash: decimal.NewFromFloat(cash)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/loans/listing", func(w http.ResponseWriter, req *http.Request) {
+		f, err := os.Open(filepath.Join("../fixtures", "listed_loans.json"))
+		require.NoError(t, err)
+		defer f.Close()
+		io.Copy(w, f)
+	})
+	mux.HandleFunc(fmt.Sprintf("/accounts/%d/availablecash", testInvestorID), func(w http.ResponseWriter, req *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		fmt.Fprintf(w, `{"investorId": %d, "availableCash": %s}`, testInvestorID, api.cash)
+	})
+	mux.HandleFunc(fmt.Sprintf("/accounts/%d/summary", testInvestorID), func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, `{"investorId": %d, "accountTotal": 10000}`, testInvestorID)
+	})
+	mux.HandleFunc(fmt.Sprintf("/accounts/%d/detailednotes", testInvestorID), func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, `{"myNotes": [{"loanId": 50002, "noteAmount": 25, "grade": "D3", "purpose": "Credit card refinancing"}]}`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/accounts/%d/orders", testInvestorID), api.submitOrder)
+
+	return api, httptest.NewServer(mux)
+}
+
+func (api *fakeAPI) submitOrder(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Orders    []lendingclub.OrderSubmission `json:"orders"`
+		AccountID int                           `json:"aid"`
+	}
+	require.NoError(api.t, json.NewDecoder(req.Body).Decode(&body))
+	assert.Equal(api.t, testInvestorID, body.AccountID)
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.orders = append(api.orders, body.Orders)
+	if api.failWith != 0 {
+		w.WriteHeader(api.failWith)
+		return
+	}
+
+	instruct := lendingclub.OrderInstruct{ID: 777}
+	for _, o := range body.Orders {
+		oc := lendingclub.OrderConfirmation{
+			LoanID:          o.LoanID,
+			RequestedAmount: o.Amount,
+			InvestedAmount:  o.Amount,
+			ExecutionStatus: lendingclub.ExecutionStatuses{lendingclub.OrderFulfilled},
+		}
+		if api.partial {
+			oc.InvestedAmount = lendingclub.NoteIncrement
+			oc.ExecutionStatus = lendingclub.ExecutionStatuses{lendingclub.OrderLoanAmountExceeded}
+		}
+		api.cash = api.cash.Sub(oc.InvestedAmount)
+		instruct.OrderConfirmations = append(instruct.OrderConfirmations, oc)
+	}
+	json.NewEncoder(w).Encode(instruct)
+}
+
+func testEngine(url string, store Store) *Engine {
+	c := lendingclub.NewClientWithOptions("Token", lendingclub.WithBaseURL(url))
+
+	return &Engine{
+		Accounts:      c.Accounts(testInvestorID),
+		Loans:         c.Loans(),
+		AmountPerLoan: decimal.New(50, 0),
+		Store:         store,
+		now: func() time.Time {
+			return time.Date(2015, 6, 5, 14, 0, 0, 0, time.UTC)
+		},
+	}
+}
+
+func TestEngineRun(t *testing.T) {
+	api, ts := newFakeAPI(t, 1000)
+	defer ts.Close()
+
+	store := NewMemoryStore()
+	e := testEngine(ts.URL, store)
+	e.Filter = filter.MustCompile(`grade in ["A", "B"]`)
+
+	report, err := e.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Listed)
+	require.Len(t, report.Rejected, 1)
+	assert.Equal(t, 50002, report.Rejected[0].Loan.ID)
+
+	require.Len(t, api.orders, 1)
+	require.Len(t, api.orders[0], 1)
+	assert.Equal(t, 50001, api.orders[0][0].LoanID)
+	assert.Equal(t, "50", api.orders[0][0].Amount.String())
+
+	require.Len(t, report.Purchases, 1)
+	assert.Equal(t, 777, report.Purchases[0].OrderID)
+	assert.Equal(t, "50", report.Invested.String())
+	assert.Len(t, store.Purchases(), 1)
+
+	// A second run must not buy into the same loan again.
+	report, err = e.Run(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, report.Orders)
+	require.Len(t, report.Skipped, 1)
+	assert.Equal(t, "already invested", report.Skipped[0].Reason)
+	assert.Len(t, api.orders, 1)
+}
+
+func TestEnginePartialFillWithoutFulfilled(t *testing.T) {
+	api, ts := newFakeAPI(t, 1000)
+	defer ts.Close()
+	api.partial = true
+
+	store := NewMemoryStore()
+	e := testEngine(ts.URL, store)
+	e.Filter = filter.MustCompile(`grade in ["A", "B"]`)
+
+	report, err := e.Run(context.Background())
+	require.NoError(t, err)
+
+	// Money was invested, so the loan is recorded whatever the status says.
+	require.Len(t, report.Purchases, 1)
+	assert.Equal(t, "25", report.Invested.String())
+	assert.Equal(t, "LOAN_AMNT_EXCEEDED", report.Purchases[0].ExecutionStatus)
+
+	spent, err := store.SpentSince(e.startOfDay())
+	require.NoError(t, err)
+	assert.Equal(t, "25", spent.String())
+
+	report, err = e.Run(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, report.Orders)
+	assert.Len(t, api.orders, 1)
+}
+
+func TestEngineSubmitFailure(t *testing.T) {
+	api, ts := newFakeAPI(t, 1000)
+	defer ts.Close()
+	api.failWith = http.StatusBadGateway
+
+	store := NewMemoryStore()
+	e := testEngine(ts.URL, store)
+	e.Filter = filter.MustCompile(`grade in ["A", "B"]`)
+
+	_, err := e.Run(context.Background())
+	require.Error(t, err)
+
+	// The order may have been placed, so the loan is never bought into again.
+	purchases := store.Purchases()
+	require.Len(t, purchases, 1)
+	assert.Equal(t, 50001, purchases[0].LoanID)
+	assert.Equal(t, UnconfirmedStatus, purchases[0].ExecutionStatus)
+
+	report, err := e.Run(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, report.Orders)
+	assert.Len(t, api.orders, 1)
+
+	// A rejected request placed nothing.
+	store = NewMemoryStore()
+	e.Store = store
+	api.failWith = http.StatusBadRequest
+	_, err = e.Run(context.Background())
+	require.Error(t, err)
+	assert.Empty(t, store.Purchases())
+}
+
+func TestEngineDryRun(t *testing.T) {
+	api, ts := newFakeAPI(t, 1000)
+	defer ts.Close()
+
+	store := NewMemoryStore()
+	e := testEngine(ts.URL, store)
+	e.DryRun = true
+
+	report, err := e.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, report.DryRun)
+	assert.Len(t, report.Orders, 2)
+	assert.Empty(t, api.orders)
+	assert.Empty(t, store.Purchases())
+}
+
+func TestEngineCaps(t *testing.T) {
+	api, ts := newFakeAPI(t, 1000)
+	defer ts.Close()
+
+	store := NewMemoryStore()
+	store.Record(Purchase{LoanID: 1, Amount: decimal.New(175, 0), Time: time.Date(2015, 6, 5, 9, 0, 0, 0, time.UTC)})
+	store.Record(Purchase{LoanID: 2, Amount: decimal.New(500, 0), Time: time.Date(2015, 6, 4, 9, 0, 0, 0, time.UTC)})
+
+	e := testEngine(ts.URL, store)
+	e.Location = time.UTC
+	e.MaxPerDay = decimal.New(250, 0)
+
+	report, err := e.Run(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, report.Orders, 1)
+	assert.Equal(t, 50001, report.Orders[0].LoanID)
+	require.Len(t, report.Skipped, 1)
+	assert.Equal(t, 50002, report.Skipped[0].Loan.ID)
+	assert.Equal(t, "budget exhausted", report.Skipped[0].Reason)
+	assert.Len(t, api.orders, 1)
+}
+
+func TestEngineCashLimit(t *testing.T) {
+	_, ts := newFakeAPI(t, 60)
+	defer ts.Close()
+
+	e := testEngine(ts.URL, NewMemoryStore())
+	e.DryRun = true
+	e.AmountPerLoan = decimal.New(40, 0)
+
+	report, err := e.Run(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, report.Orders, 2)
+	assert.Equal(t, "25", report.Orders[0].Amount.String())
+	assert.Equal(t, "25", report.Orders[1].Amount.String())
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "invest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "purchases.json")
+	fs, err := OpenFileStore(path)
+	require.NoError(t, err)
+
+	now := time.Date(2015, 6, 5, 14, 0, 0, 0, time.UTC)
+	require.NoError(t, fs.Record(Purchase{LoanID: 50001, Amount: decimal.New(25, 0), Time: now}))
+
+	fs, err = OpenFileStore(path)
+	require.NoError(t, err)
+
+	owned, err := fs.Purchased(50001)
+	require.NoError(t, err)
+	assert.True(t, owned)
+
+	spent, err := fs.SpentSince(now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "25", spent.String())
+}
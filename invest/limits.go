@@ -0,0 +1,291 @@
+package invest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.New(100, 0)
+
+// Holding is money already invested in a loan.
+type Holding struct {
+	LoanID  int
+	Amount  decimal.Decimal
+	Grade   lendingclub.Grade
+	State   string
+	Purpose lendingclub.Purpose
+}
+
+// NoteHoldings converts notes to holdings. The notes endpoint does not
+// report the borrower's state or the loan's purpose, so those holdings only
+// count towards the loan and grade limits unless State and Purpose are
+// filled in.
+func NoteHoldings(notes []lendingclub.Note) []Holding {
+	holdings := make([]Holding, len(notes))
+	for i, n := range notes {
+		holdings[i] = Holding{
+			LoanID: int(n.LoanID.IntPart()),
+			Amount: n.Amount,
+			Grade:  n.Grade.Grade(),
+		}
+	}
+
+	return holdings
+}
+
+// StateLookup returns the borrower's state of a loan, or false if it is not
+// known.
+type StateLookup func(loanID int) (string, bool)
+
+// DetailedNoteHoldings converts detailed notes to holdings, taking the
+// purpose from the note and the borrower's state from loans, the listed
+// loans, or else from states, which may be nil. Notes whose state neither
+// knows have no State.
+func DetailedNoteHoldings(notes []lendingclub.DetailedNote, loans []lendingclub.Loan, states StateLookup) []Holding {
+	listed := make(map[int]string, len(loans))
+	for _, loan := range loans {
+		listed[loan.ID] = loan.AddressState
+	}
+
+	holdings := make([]Holding, len(notes))
+	for i, n := range notes {
+		loanID := int(n.LoanID.IntPart())
+		state, ok := listed[loanID]
+		if !ok && states != nil {
+			state, _ = states(loanID)
+		}
+
+		holdings[i] = Holding{
+			LoanID:  loanID,
+			Amount:  n.Amount,
+			Grade:   n.Grade.Grade(),
+			State:   state,
+			Purpose: n.Purpose,
+		}
+	}
+
+	return holdings
+}
+
+// LimitMode is what Limits does with orders that break a limit.
+type LimitMode int
+
+const (
+	// TrimOrders shrinks orders to what the limits allow, dropping those
+	// left with nothing.
+	TrimOrders LimitMode = iota
+	// RejectOrders fails the whole submission.
+	RejectOrders
+)
+
+// Limits caps how much of the account value may be exposed to a single
+// loan, state, grade or purpose. Caps are percentages of the account total;
+// a zero or missing cap is no limit.
+type Limits struct {
+	MaxPerLoan    decimal.Decimal
+	MaxPerState   decimal.Decimal
+	MaxPerGrade   map[lendingclub.Grade]decimal.Decimal
+	MaxPerPurpose map[lendingclub.Purpose]decimal.Decimal
+
+	// States resolves the borrower's state of held loans that are no
+	// longer listed, which is every loan once it is issued. The notes
+	// endpoints do not report it, so it has to come from elsewhere, such
+	// as Lending Club's loan data downloads or a record kept when the note
+	// was bought. CheckAccount needs it when MaxPerState is set.
+	States StateLookup
+
+	Mode LimitMode
+}
+
+// Adjustment explains why an order was trimmed or rejected. Allowed is what
+// the order was trimmed to.
+type Adjustment struct {
+	Index     int
+	LoanID    int
+	Requested decimal.Decimal
+	Allowed   decimal.Decimal
+	Reasons   []string
+}
+
+func (a Adjustment) String() string {
+	return fmt.Sprintf("order %d (loan %d) %s -> %s: %s", a.Index, a.LoanID, a.Requested, a.Allowed, strings.Join(a.Reasons, "; "))
+}
+
+// LimitError is returned when orders are rejected for breaking limits. It
+// matches lendingclub.ErrValidation with errors.Is.
+type LimitError []Adjustment
+
+func (e LimitError) Error() string {
+	adjs := make([]string, len(e))
+	for i, a := range e {
+		adjs[i] = a.String()
+	}
+
+	return fmt.Sprintf("invest: %d orders exceed limits: %s", len(e), strings.Join(adjs, "; "))
+}
+
+func (e LimitError) Is(target error) bool {
+	return target == lendingclub.ErrValidation
+}
+
+// CheckAccount checks orders against the detailed notes and account total of
+// ar, taking the state of held loans from loans or States. Since a held
+// note's state or purpose may be unknown, it fails rather than undercount
+// exposure when a state or purpose cap is set and any holding lacks that
+// field.
+func (l *Limits) CheckAccount(ctx context.Context, ar *lendingclub.AccountsResource, loans []lendingclub.Loan, orders []lendingclub.OrderSubmission) ([]lendingclub.OrderSubmission, []Adjustment, error) {
+	summary, err := ar.SummaryContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	notes, err := ar.DetailedNotesContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	holdings := DetailedNoteHoldings(notes, loans, l.States)
+	if err := l.complete(holdings); err != nil {
+		return nil, nil, err
+	}
+
+	return l.Check(holdings, summary.AccountTotal, loans, orders)
+}
+
+// complete checks that holdings carry every field a cap is set on.
+func (l *Limits) complete(holdings []Holding) error {
+	capsPurpose := false
+	for _, limit := range l.MaxPerPurpose {
+		if limit.Sign() > 0 {
+			capsPurpose = true
+		}
+	}
+
+	for _, h := range holdings {
+		switch {
+		case l.MaxPerState.Sign() > 0 && h.State == "":
+			return fmt.Errorf("%w: the state of loan %d is not listed or known to States, so the per-state cap cannot be checked", lendingclub.ErrValidation, h.LoanID)
+		case capsPurpose && h.Purpose == "":
+			return fmt.Errorf("%w: the purpose of loan %d is unknown, so the per-purpose cap cannot be checked", lendingclub.ErrValidation, h.LoanID)
+		}
+	}
+
+	return nil
+}
+
+// exposure is the amount held per key of one kind of limit.
+type exposure struct {
+	name string
+	held map[string]decimal.Decimal
+}
+
+func (e *exposure) headroom(key string, limit, accountTotal decimal.Decimal) decimal.Decimal {
+	return limit.Mul(accountTotal).Div(hundred).Sub(e.held[key])
+}
+
+func (e *exposure) add(key string, amount decimal.Decimal) {
+	if held, ok := e.held[key]; ok {
+		e.held[key] = held.Add(amount)
+	} else {
+		e.held[key] = amount
+	}
+}
+
+// Check checks orders, in sequence, against the holdings and accountTotal.
+// loans are the listed loans the orders are for. In TrimOrders mode it
+// returns the orders that fit, trimmed to multiples of lendingclub.NoteIncrement, and
+// an adjustment for every order changed. In RejectOrders mode any
+// adjustment fails the check with a LimitError.
+func (l *Limits) Check(holdings []Holding, accountTotal decimal.Decimal, loans []lendingclub.Loan, orders []lendingclub.OrderSubmission) ([]lendingclub.OrderSubmission, []Adjustment, error) {
+	byID := make(map[int]lendingclub.Loan, len(loans))
+	for _, loan := range loans {
+		byID[loan.ID] = loan
+	}
+
+	perLoan := &exposure{name: "loan", held: make(map[string]decimal.Decimal)}
+	perState := &exposure{name: "state", held: make(map[string]decimal.Decimal)}
+	perGrade := &exposure{name: "grade", held: make(map[string]decimal.Decimal)}
+	perPurpose := &exposure{name: "purpose", held: make(map[string]decimal.Decimal)}
+	for _, h := range holdings {
+		perLoan.add(fmt.Sprint(h.LoanID), h.Amount)
+		if h.State != "" {
+			perState.add(h.State, h.Amount)
+		}
+		if h.Grade != "" {
+			perGrade.add(h.Grade.String(), h.Amount)
+		}
+		if h.Purpose != "" {
+			perPurpose.add(h.Purpose.String(), h.Amount)
+		}
+	}
+
+	var (
+		allowed     []lendingclub.OrderSubmission
+		adjustments []Adjustment
+	)
+	for i, o := range orders {
+		adj := Adjustment{Index: i, LoanID: o.LoanID, Requested: o.Amount, Allowed: o.Amount}
+
+		loan, ok := byID[o.LoanID]
+		if !ok {
+			adj.Allowed = decimal.Zero
+			adj.Reasons = append(adj.Reasons, "loan is not listed, so its limits cannot be checked")
+			adjustments = append(adjustments, adj)
+			continue
+		}
+
+		checks := []struct {
+			exposure *exposure
+			key      string
+			limit    decimal.Decimal
+		}{
+			{perLoan, fmt.Sprint(loan.ID), l.MaxPerLoan},
+			{perState, loan.AddressState, l.MaxPerState},
+			{perGrade, loan.Grade.String(), l.MaxPerGrade[loan.Grade]},
+			{perPurpose, loan.Purpose.String(), l.MaxPerPurpose[loan.Purpose]},
+		}
+		for _, c := range checks {
+			if c.limit.Sign() <= 0 || c.key == "" {
+				continue
+			}
+
+			headroom := c.exposure.headroom(c.key, c.limit, accountTotal)
+			if !headroom.LessThan(adj.Allowed) {
+				continue
+			}
+			if headroom.Sign() < 0 {
+				headroom = decimal.Zero
+			}
+
+			adj.Allowed = lendingclub.FloorIncrement(headroom)
+			adj.Reasons = append(adj.Reasons, fmt.Sprintf("%s %s holds %s of the %s%% cap (%s of %s), leaving %s",
+				c.exposure.name, c.key, c.exposure.held[c.key].StringFixed(2), c.limit,
+				c.limit.Mul(accountTotal).Div(hundred).StringFixed(2), accountTotal, headroom.StringFixed(2)))
+		}
+
+		if len(adj.Reasons) > 0 {
+			adjustments = append(adjustments, adj)
+		}
+		if adj.Allowed.Sign() <= 0 {
+			continue
+		}
+
+		for _, c := range checks {
+			if c.key != "" {
+				c.exposure.add(c.key, adj.Allowed)
+			}
+		}
+		o.Amount = adj.Allowed
+		allowed = append(allowed, o)
+	}
+
+	if l.Mode == RejectOrders && len(adjustments) > 0 {
+		return nil, adjustments, LimitError(adjustments)
+	}
+
+	return allowed, adjustments, nil
+}
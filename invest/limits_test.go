@@ -0,0 +1,146 @@
+package invest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLimits() *Limits {
+	return &Limits{
+		MaxPerLoan:    decimal.New(5, -1),
+		MaxPerState:   decimal.New(20, 0),
+		MaxPerGrade:   map[lendingclub.Grade]decimal.Decimal{lendingclub.GradeD: decimal.New(10, 0)},
+		MaxPerPurpose: map[lendingclub.Purpose]decimal.Decimal{lendingclub.PurposeCreditCard: decimal.New(30, 0)},
+	}
+}
+
+func testCheck(l *Limits) ([]lendingclub.OrderSubmission, []Adjustment, error) {
+	loans := []lendingclub.Loan{
+		{ID: 1, Grade: lendingclub.GradeA, AddressState: "CA", Purpose: lendingclub.PurposeDebtConsolidation},
+		{ID: 2, Grade: lendingclub.GradeD, AddressState: "NY", Purpose: lendingclub.PurposeCreditCard},
+		{ID: 3, Grade: lendingclub.GradeB, AddressState: "CA", Purpose: lendingclub.PurposeCreditCard},
+	}
+	holdings := []Holding{
+		{LoanID: 1, Amount: decimal.New(25, 0), Grade: lendingclub.GradeA},
+		{LoanID: 9, Amount: decimal.New(1950, 0), Grade: lendingclub.GradeB, State: "CA"},
+		{LoanID: 8, Amount: decimal.New(980, 0), Grade: lendingclub.GradeD},
+	}
+	orders := []lendingclub.OrderSubmission{
+		{LoanID: 1, Amount: decimal.New(100, 0)},
+		{LoanID: 2, Amount: decimal.New(50, 0)},
+		{LoanID: 3, Amount: decimal.New(50, 0), PortfolioID: 7},
+		{LoanID: 4, Amount: decimal.New(25, 0)},
+	}
+
+	return l.Check(holdings, decimal.New(10000, 0), loans, orders)
+}
+
+func TestLimitsTrim(t *testing.T) {
+	orders, adjustments, err := testCheck(testLimits())
+	require.NoError(t, err)
+
+	// Loan 1 is trimmed to its per-loan headroom, loan 2 has no room left in
+	// grade D, loan 3 gets what is left in CA after loan 1 and loan 4 is not
+	// listed.
+	require.Len(t, orders, 2)
+	assert.Equal(t, 1, orders[0].LoanID)
+	assert.Equal(t, "25", orders[0].Amount.String())
+	assert.Equal(t, 3, orders[1].LoanID)
+	assert.Equal(t, "25", orders[1].Amount.String())
+	assert.Equal(t, 7, orders[1].PortfolioID)
+
+	require.Len(t, adjustments, 4)
+	assert.Equal(t, "25", adjustments[0].Allowed.String())
+	assert.Equal(t, []string{"loan 1 holds 25.00 of the 0.5% cap (50.00 of 10000), leaving 25.00"}, adjustments[0].Reasons)
+	assert.Equal(t, "0", adjustments[1].Allowed.String())
+	assert.Equal(t, []string{"grade D holds 980.00 of the 10% cap (1000.00 of 10000), leaving 20.00"}, adjustments[1].Reasons)
+	assert.Equal(t, 2, adjustments[2].Index)
+	assert.Equal(t, []string{"state CA holds 1975.00 of the 20% cap (2000.00 of 10000), leaving 25.00"}, adjustments[2].Reasons)
+	assert.Equal(t, 4, adjustments[3].LoanID)
+	assert.Contains(t, adjustments[3].String(), "order 3 (loan 4) 25 -> 0: loan is not listed")
+}
+
+func TestLimitsReject(t *testing.T) {
+	l := testLimits()
+	l.Mode = RejectOrders
+
+	orders, adjustments, err := testCheck(l)
+	require.Error(t, err)
+	assert.Nil(t, orders)
+	assert.Len(t, adjustments, 4)
+	assert.True(t, errors.Is(err, lendingclub.ErrValidation))
+
+	var lerr LimitError
+	require.True(t, errors.As(err, &lerr))
+	assert.Len(t, lerr, 4)
+}
+
+func TestEngineLimits(t *testing.T) {
+	api, ts := newFakeAPI(t, 1000)
+	defer ts.Close()
+
+	e := testEngine(ts.URL, NewMemoryStore())
+	e.Limits = &Limits{
+		MaxPerLoan: decimal.New(25, -2),
+	}
+
+	report, err := e.Run(context.Background())
+	require.NoError(t, err)
+
+	// Each order is capped at 25, which the note already held in loan
+	// 50002 uses up.
+	require.Len(t, api.orders, 1)
+	require.Len(t, api.orders[0], 1)
+	assert.Equal(t, 50001, api.orders[0][0].LoanID)
+	assert.Equal(t, "25", api.orders[0][0].Amount.String())
+
+	require.Len(t, report.Adjustments, 2)
+	assert.Equal(t, "25", report.Adjustments[0].Allowed.String())
+	assert.Equal(t, 50002, report.Adjustments[1].LoanID)
+	assert.Equal(t, "0", report.Adjustments[1].Allowed.String())
+	assert.Equal(t, []string{"loan 50002 holds 25.00 of the 0.25% cap (25.00 of 10000), leaving 0.00"}, report.Adjustments[1].Reasons)
+}
+
+func TestCheckAccountHoldings(t *testing.T) {
+	_, ts := newFakeAPI(t, 1000)
+	defer ts.Close()
+
+	// The note held in loan 50002 is issued, so the loan is no longer
+	// listed and its state comes from States.
+	ar := testEngine(ts.URL, NewMemoryStore()).Accounts
+	loans := []lendingclub.Loan{
+		{ID: 50001, Grade: lendingclub.GradeA, AddressState: "CA", Purpose: lendingclub.PurposeDebtConsolidation},
+		{ID: 50003, Grade: lendingclub.GradeC, AddressState: "NY", Purpose: lendingclub.PurposeCreditCard},
+	}
+	orders := []lendingclub.OrderSubmission{{LoanID: 50003, Amount: decimal.New(50, 0)}}
+	issued := map[int]string{50002: "NY"}
+
+	l := &Limits{
+		MaxPerState:   decimal.New(5, -1),
+		MaxPerPurpose: map[lendingclub.Purpose]decimal.Decimal{lendingclub.PurposeCreditCard: decimal.New(25, -2)},
+		States: func(loanID int) (string, bool) {
+			state, ok := issued[loanID]
+			return state, ok
+		},
+	}
+	_, adjustments, err := l.CheckAccount(context.Background(), ar, loans, orders)
+	require.NoError(t, err)
+	require.Len(t, adjustments, 1)
+	assert.Equal(t, "0", adjustments[0].Allowed.String())
+	assert.Equal(t, []string{
+		"state NY holds 25.00 of the 0.5% cap (50.00 of 10000), leaving 25.00",
+		"purpose credit_card holds 25.00 of the 0.25% cap (25.00 of 10000), leaving 0.00",
+	}, adjustments[0].Reasons)
+
+	// Only loans States cannot place fail the check.
+	delete(issued, 50002)
+	_, _, err = l.CheckAccount(context.Background(), ar, loans, orders)
+	assert.True(t, errors.Is(err, lendingclub.ErrValidation))
+	assert.Contains(t, err.Error(), "the state of loan 50002 is not listed or known to States")
+}
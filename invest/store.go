@@ -0,0 +1,151 @@
+package invest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Purchase is an investment the engine made in a loan.
+type Purchase struct {
+	LoanID          int             `json:"loanId"`
+	OrderID         int             `json:"orderInstructId"`
+	Amount          decimal.Decimal `json:"amount"`
+	ExecutionStatus string          `json:"executionStatus"`
+	Time            time.Time       `json:"time"`
+}
+
+// Store remembers purchases so the engine never buys into the same loan
+// twice and can enforce daily spending caps across runs.
+type Store interface {
+	Purchased(loanID int) (bool, error)
+	Record(p Purchase) error
+	SpentSince(t time.Time) (decimal.Decimal, error)
+}
+
+// MemoryStore is a Store that only lives as long as the process.
+type MemoryStore struct {
+	mu        sync.Mutex
+	purchases []Purchase
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (ms *MemoryStore) Purchased(loanID int) (bool, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for _, p := range ms.purchases {
+		if p.LoanID == loanID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (ms *MemoryStore) Record(p Purchase) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.purchases = append(ms.purchases, p)
+	return nil
+}
+
+func (ms *MemoryStore) SpentSince(t time.Time) (decimal.Decimal, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	spent := decimal.Zero
+	for _, p := range ms.purchases {
+		if !p.Time.Before(t) {
+			spent = spent.Add(p.Amount)
+		}
+	}
+
+	return spent, nil
+}
+
+// Purchases returns a copy of the recorded purchases.
+func (ms *MemoryStore) Purchases() []Purchase {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return append([]Purchase(nil), ms.purchases...)
+}
+
+// FileStore is a Store persisted as a JSON file. Every Record rewrites the
+// file, so it suits the handful of purchases an engine makes per run.
+type FileStore struct {
+	path string
+	mem  MemoryStore
+}
+
+// OpenFileStore loads the purchases recorded in path, which does not need to
+// exist yet.
+func OpenFileStore(path string) (*FileStore, error) {
+	fs := &FileStore{path: path}
+
+	bs, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(bs, &fs.mem.purchases); err != nil {
+		return nil, err
+	}
+
+	return fs, nil
+}
+
+func (fs *FileStore) Purchased(loanID int) (bool, error) {
+	return fs.mem.Purchased(loanID)
+}
+
+func (fs *FileStore) SpentSince(t time.Time) (decimal.Decimal, error) {
+	return fs.mem.SpentSince(t)
+}
+
+func (fs *FileStore) Record(p Purchase) error {
+	fs.mem.mu.Lock()
+	defer fs.mem.mu.Unlock()
+
+	purchases := append(fs.mem.purchases, p)
+	bs, err := json.MarshalIndent(purchases, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated
+	// store behind.
+	tmp, err := ioutil.TempFile(filepath.Dir(fs.path), filepath.Base(fs.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(bs); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), fs.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	fs.mem.purchases = purchases
+	return nil
+}
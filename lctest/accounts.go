@@ -0,0 +1,386 @@
+package lctest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+)
+
+// accountHandler serves the endpoints of a single investor account. Every
+// handler locks the server since orders also change the listed loans.
+type accountHandler struct {
+	s    *Server
+	acct *account
+}
+
+func (h accountHandler) lock() func() {
+	h.s.mu.Lock()
+	return h.s.mu.Unlock
+}
+
+func (h accountHandler) availableCash(w http.ResponseWriter, req *http.Request) {
+	defer h.lock()()
+
+	writeJSON(w, lendingclub.AvailableCash{InvestorID: h.acct.investorID, AvailableCash: h.acct.cash})
+}
+
+func (h accountHandler) summary(w http.ResponseWriter, req *http.Request) {
+	defer h.lock()()
+
+	sum := lendingclub.Summary{
+		AvailableCash:        h.acct.cash,
+		InvestorID:           h.acct.investorID,
+		AccruedInterest:      decimal.Zero,
+		OutstandingPrincipal: decimal.Zero,
+		InFundingBalance:     decimal.Zero,
+		ReceivedInterest:     decimal.Zero,
+		ReceivedPrincipal:    decimal.Zero,
+		ReceivedLateFees:     decimal.Zero,
+		TotalNotes:           len(h.acct.notes),
+		TotalPortfolios:      len(h.acct.portfolios),
+	}
+	for _, n := range h.acct.notes {
+		if n.LoanStatus == lendingclub.LoanInFunding {
+			sum.InFundingBalance = sum.InFundingBalance.Add(n.Amount)
+		} else {
+			sum.OutstandingPrincipal = sum.OutstandingPrincipal.Add(n.Amount.Sub(n.PrincipalReceived))
+		}
+		sum.ReceivedInterest = sum.ReceivedInterest.Add(n.InterestReceived)
+		sum.ReceivedPrincipal = sum.ReceivedPrincipal.Add(n.PrincipalReceived)
+		sum.ReceivedLateFees = sum.ReceivedLateFees.Add(n.LateFeesReceived)
+	}
+	sum.AccountTotal = sum.AvailableCash.Add(sum.InFundingBalance).Add(sum.OutstandingPrincipal)
+
+	writeJSON(w, sum)
+}
+
+func (h accountHandler) addFunds(w http.ResponseWriter, req *http.Request) {
+	var fp lendingclub.FundsPayload
+	if err := json.NewDecoder(req.Body).Decode(&fp); err != nil {
+		validationError(w, "", "malformed request: %v", err)
+		return
+	}
+	if fp.Amount.Sign() <= 0 {
+		validationError(w, "amount", "amount must be positive")
+		return
+	}
+	if fp.TransferFrequency == "" {
+		validationError(w, "transferFrequency", "transfer frequency is required")
+		return
+	}
+
+	defer h.lock()()
+
+	date := lendingclub.Time{Time: h.s.now()}
+	if fp.StartDate != nil {
+		date = *fp.StartDate
+	}
+	t := &lendingclub.Transfer{
+		TransferID:   h.s.newID(),
+		TransferDate: date,
+		Amount:       fp.Amount,
+		Status:       lendingclub.TransferPending,
+		Frequency:    fp.TransferFrequency,
+		Operation:    lendingclub.TransferDeposit,
+		Cancellable:  true,
+	}
+	if fp.EndDate != nil {
+		t.EndDate = *fp.EndDate
+	}
+	h.acct.transfers[t.TransferID] = t
+
+	writeJSON(w, lendingclub.Deposit{
+		FundsPayload:               fp,
+		InvestorID:                 h.acct.investorID,
+		Frequency:                  fp.TransferFrequency,
+		EstimatedFundsTransferDate: date,
+	})
+}
+
+func (h accountHandler) withdrawFunds(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Amount decimal.Decimal `json:"amount"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		validationError(w, "", "malformed request: %v", err)
+		return
+	}
+
+	defer h.lock()()
+
+	if body.Amount.Sign() <= 0 {
+		validationError(w, "amount", "amount must be positive")
+		return
+	}
+	if h.acct.cash.LessThan(body.Amount) {
+		validationError(w, "amount", "amount exceeds the available cash of %s", h.acct.cash)
+		return
+	}
+
+	// Withdrawn cash is no longer available while the transfer is pending
+	// and comes back if it is cancelled.
+	h.acct.cash = h.acct.cash.Sub(body.Amount)
+	now := lendingclub.Time{Time: h.s.now()}
+	t := &lendingclub.Transfer{
+		TransferID:   h.s.newID(),
+		TransferDate: now,
+		Amount:       body.Amount,
+		Status:       lendingclub.TransferPending,
+		Frequency:    lendingclub.LoadNow,
+		Operation:    lendingclub.TransferWithdrawal,
+		Cancellable:  true,
+	}
+	h.acct.transfers[t.TransferID] = t
+
+	writeJSON(w, lendingclub.Withdrawal{
+		Amount:                     body.Amount,
+		InvestorID:                 h.acct.investorID,
+		EstimatedFundsTransferDate: now,
+	})
+}
+
+func (h accountHandler) pendingFunds(w http.ResponseWriter, req *http.Request) {
+	defer h.lock()()
+
+	transfers := make(map[int]lendingclub.Transfer, len(h.acct.transfers))
+	for id, t := range h.acct.transfers {
+		transfers[id] = *t
+	}
+
+	writeJSON(w, struct {
+		Transfers map[int]lendingclub.Transfer `json:"transfers"`
+	}{transfers})
+}
+
+func (h accountHandler) cancelFunds(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		TransferIDs []int `json:"transferIds"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		validationError(w, "", "malformed request: %v", err)
+		return
+	}
+
+	defer h.lock()()
+
+	cr := lendingclub.CancellationResult{InvestorID: h.acct.investorID}
+	for _, id := range body.TransferIDs {
+		c := lendingclub.Cancellation{TransferID: id, Status: "SUCCESS"}
+
+		t, ok := h.acct.transfers[id]
+		switch {
+		case !ok:
+			c.Status, c.Message = "FAILED", "transfer not found"
+		case !t.Cancellable:
+			c.Status, c.Message = "FAILED", "transfer cannot be cancelled"
+		default:
+			if t.Operation == lendingclub.TransferWithdrawal {
+				h.acct.cash = h.acct.cash.Add(t.Amount)
+			}
+			delete(h.acct.transfers, id)
+		}
+		cr.Cancellations = append(cr.Cancellations, c)
+	}
+
+	writeJSON(w, cr)
+}
+
+func (h accountHandler) notes(w http.ResponseWriter, req *http.Request) {
+	defer h.lock()()
+
+	notes := make([]lendingclub.Note, len(h.acct.notes))
+	for i, n := range h.acct.notes {
+		notes[i] = lendingclub.Note{
+			ID:               n.ID,
+			Amount:           n.Amount,
+			LoanID:           n.LoanID,
+			OrderID:          n.OrderID,
+			InterestRate:     n.InterestRate,
+			LoanStatus:       n.LoanStatus,
+			Grade:            n.Grade,
+			LoanAmount:       n.LoanAmount,
+			LoanLength:       n.LoanLength,
+			OrderDate:        n.OrderDate,
+			PaymentsReceived: n.PaymentsReceived,
+		}
+	}
+
+	writeJSON(w, struct {
+		Notes []lendingclub.Note `json:"myNotes"`
+	}{notes})
+}
+
+func (h accountHandler) detailedNotes(w http.ResponseWriter, req *http.Request) {
+	defer h.lock()()
+
+	writeJSON(w, struct {
+		Notes []lendingclub.DetailedNote `json:"myNotes"`
+	}{h.acct.notes})
+}
+
+func (h accountHandler) portfolios(w http.ResponseWriter, req *http.Request) {
+	defer h.lock()()
+
+	portfolios := make([]lendingclub.Portfolio, 0, len(h.acct.portfolios))
+	for _, p := range h.acct.portfolios {
+		portfolios = append(portfolios, *p)
+	}
+	sort.Slice(portfolios, func(i, j int) bool { return portfolios[i].ID < portfolios[j].ID })
+
+	writeJSON(w, struct {
+		Portfolios []lendingclub.Portfolio `json:"myPortfolios"`
+	}{portfolios})
+}
+
+func (h accountHandler) createPortfolio(w http.ResponseWriter, req *http.Request) {
+	var p lendingclub.Portfolio
+	if err := json.NewDecoder(req.Body).Decode(&p); err != nil {
+		validationError(w, "", "malformed request: %v", err)
+		return
+	}
+
+	defer h.lock()()
+
+	if !h.validPortfolioName(w, 0, p.Name) {
+		return
+	}
+
+	p.ID = h.s.newID()
+	h.acct.portfolios[p.ID] = &p
+
+	writeJSON(w, p)
+}
+
+// portfolio serves /portfolios/{id} and /portfolios/{id}/notes.
+func (h accountHandler) portfolio(w http.ResponseWriter, req *http.Request, parts []string) {
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || len(parts) > 2 || (len(parts) == 2 && parts[1] != "notes") {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case len(parts) == 2:
+		h.s.method(w, req, "POST", func(w http.ResponseWriter, req *http.Request) { h.assignNotes(w, req, id) })
+	case req.Method == "PUT":
+		h.updatePortfolio(w, req, id)
+	default:
+		h.s.method(w, req, "DELETE", func(w http.ResponseWriter, req *http.Request) { h.deletePortfolio(w, req, id) })
+	}
+}
+
+func (h accountHandler) updatePortfolio(w http.ResponseWriter, req *http.Request, id int) {
+	var update lendingclub.Portfolio
+	if err := json.NewDecoder(req.Body).Decode(&update); err != nil {
+		validationError(w, "", "malformed request: %v", err)
+		return
+	}
+
+	defer h.lock()()
+
+	p, ok := h.acct.portfolios[id]
+	if !ok {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+	if !h.validPortfolioName(w, id, update.Name) {
+		return
+	}
+
+	p.Name, p.Description = update.Name, update.Description
+	for i := range h.acct.notes {
+		if h.acct.notes[i].PortfolioID == id {
+			h.acct.notes[i].PortfolioName = p.Name
+		}
+	}
+
+	writeJSON(w, p)
+}
+
+func (h accountHandler) deletePortfolio(w http.ResponseWriter, req *http.Request, id int) {
+	defer h.lock()()
+
+	if _, ok := h.acct.portfolios[id]; !ok {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+
+	delete(h.acct.portfolios, id)
+	for i := range h.acct.notes {
+		if h.acct.notes[i].PortfolioID == id {
+			h.acct.notes[i].PortfolioID, h.acct.notes[i].PortfolioName = 0, ""
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h accountHandler) assignNotes(w http.ResponseWriter, req *http.Request, id int) {
+	var body struct {
+		Notes []struct {
+			NoteID  int64 `json:"noteId"`
+			OrderID int64 `json:"orderId"`
+			LoanID  int64 `json:"loanId"`
+		} `json:"notes"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		validationError(w, "", "malformed request: %v", err)
+		return
+	}
+
+	defer h.lock()()
+
+	p, ok := h.acct.portfolios[id]
+	if !ok {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+
+	results := make([]lendingclub.NoteAssignment, len(body.Notes))
+	for i, ref := range body.Notes {
+		results[i] = lendingclub.NoteAssignment{
+			NoteID:  decimal.New(ref.NoteID, 0),
+			OrderID: decimal.New(ref.OrderID, 0),
+			LoanID:  decimal.New(ref.LoanID, 0),
+			Status:  "FAILED",
+			Message: "note not found",
+		}
+
+		for j := range h.acct.notes {
+			n := &h.acct.notes[j]
+			if n.ID.IntPart() == ref.NoteID {
+				n.PortfolioID, n.PortfolioName = p.ID, p.Name
+				results[i].Status, results[i].Message = "SUCCESS", ""
+				break
+			}
+		}
+	}
+
+	writeJSON(w, struct {
+		Results []lendingclub.NoteAssignment `json:"results"`
+	}{results})
+}
+
+// validPortfolioName writes a validation error and returns false if name is
+// empty or taken by a portfolio other than id. It must be called with the
+// server locked.
+func (h accountHandler) validPortfolioName(w http.ResponseWriter, id int, name string) bool {
+	if name == "" {
+		validationError(w, "portfolioName", "portfolio name is required")
+		return false
+	}
+
+	for _, p := range h.acct.portfolios {
+		if p.ID != id && strings.EqualFold(p.Name, name) {
+			validationError(w, "portfolioName", "portfolio %q already exists", name)
+			return false
+		}
+	}
+
+	return true
+}
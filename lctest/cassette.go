@@ -0,0 +1,321 @@
+package lctest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CassetteDir is where cassettes live, relative to the repository root.
+const CassetteDir = "fixtures/cassettes"
+
+// ErrNoInteraction is returned when a replayed request matches none of the
+// unused interactions on the cassette.
+var ErrNoInteraction = errors.New("lctest: no recorded interaction matches request")
+
+// Mode selects whether a Cassette records live traffic or replays it.
+type Mode int
+
+const (
+	ModeReplay Mode = iota
+	ModeRecord
+)
+
+// Interaction is a recorded request and the response it received. Account
+// IDs in /accounts/{id} paths, response headers and the ID fields of JSON
+// bodies are replaced with placeholders, and no request headers are kept, so
+// the Authorization token never reaches the disk.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   string `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Cassette is an http.RoundTripper that records request/response pairs to a
+// JSON file and replays them. Plug it into a client with
+// lendingclub.WithTransport.
+//
+// Replayed requests are matched on method, path (including the query) and
+// body; each interaction is used at most once, in recorded order, so the
+// same request can be answered differently as state changes.
+type Cassette struct {
+	mode       Mode
+	path       string
+	transport  http.RoundTripper
+	redactions []redaction
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// Load reads the cassette at path for replay. accountIDs are substituted for
+// the placeholders recorded in their place, in order.
+func Load(path string, accountIDs ...int) (*Cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var interactions []Interaction
+	if err := json.Unmarshal(b, &interactions); err != nil {
+		return nil, fmt.Errorf("lctest: reading cassette %s: %w", path, err)
+	}
+
+	return &Cassette{
+		mode:         ModeReplay,
+		path:         path,
+		redactions:   newRedactions(accountIDs),
+		interactions: interactions,
+		used:         make([]bool, len(interactions)),
+	}, nil
+}
+
+// NewRecorder returns a cassette that sends requests through transport and
+// records them for Save. accountIDs are redacted from paths, headers and
+// bodies. A nil transport uses http.DefaultTransport.
+func NewRecorder(path string, transport http.RoundTripper, accountIDs ...int) *Cassette {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &Cassette{
+		mode:       ModeRecord,
+		path:       path,
+		transport:  transport,
+		redactions: newRedactions(accountIDs),
+	}
+}
+
+// Mode reports whether the cassette records or replays.
+func (c *Cassette) Mode() Mode {
+	return c.mode
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	}
+
+	recorded := RecordedRequest{
+		Method: req.Method,
+		Path:   c.redactPath(req.URL.RequestURI()),
+		Body:   c.redactBody(string(body)),
+	}
+
+	if c.mode == ModeRecord {
+		return c.record(req, recorded)
+	}
+
+	return c.replay(req, recorded)
+}
+
+func (c *Cassette) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	res, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	header := res.Header.Clone()
+	for _, h := range []string{"Date", "Set-Cookie", "Content-Length"} {
+		header.Del(h)
+	}
+	for _, values := range header {
+		for i, v := range values {
+			values[i] = c.redactBody(c.redactPath(v))
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.interactions = append(c.interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     header,
+			Body:       c.redactBody(string(body)),
+		},
+	})
+
+	return res, nil
+}
+
+func (c *Cassette) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, in := range c.interactions {
+		if c.used[i] || !matches(in.Request, recorded) {
+			continue
+		}
+		c.used[i] = true
+
+		body := c.restore(in.Response.Body)
+		header := make(http.Header, len(in.Response.Header))
+		for k, values := range in.Response.Header {
+			for _, v := range values {
+				header.Add(k, c.restore(v))
+			}
+		}
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader([]byte(body))),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s %s (cassette %s)", ErrNoInteraction, recorded.Method, recorded.Path, recorded.Body, c.path)
+}
+
+// Unused returns the interactions a replay has not consumed yet. Tests can
+// check it is empty to make sure every recorded call still happens.
+func (c *Cassette) Unused() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var unused []Interaction
+	for i, in := range c.interactions {
+		if !c.used[i] {
+			unused = append(unused, in)
+		}
+	}
+
+	return unused
+}
+
+// Save writes the recorded interactions to the cassette's path, creating its
+// directory if needed.
+func (c *Cassette) Save() error {
+	if c.mode != ModeRecord {
+		return errors.New("lctest: only recording cassettes can be saved")
+	}
+
+	c.mu.Lock()
+	b, err := json.MarshalIndent(c.interactions, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.path, append(b, '\n'), 0644)
+}
+
+// idFields are the JSON fields that carry account IDs, matched ignoring case.
+var idFields = []string{"aid", "investorId", "accountId"}
+
+// redaction replaces one account ID with its placeholder.
+type redaction struct {
+	id          string
+	placeholder string
+	path        *regexp.Regexp
+	field       *regexp.Regexp
+}
+
+func newRedactions(accountIDs []int) []redaction {
+	redactions := make([]redaction, len(accountIDs))
+	for i, accountID := range accountIDs {
+		id := strconv.Itoa(accountID)
+		redactions[i] = redaction{
+			id:          id,
+			placeholder: fmt.Sprintf("{{account%d}}", i),
+			path:        regexp.MustCompile(`/accounts/` + id + `([/?#]|$)`),
+			field:       regexp.MustCompile(`("(?i:` + strings.Join(idFields, "|") + `)"\s*:\s*"?)` + id + `\b`),
+		}
+	}
+
+	return redactions
+}
+
+// redactPath replaces account IDs in the /accounts/{id} segment of a URL
+// path.
+func (c *Cassette) redactPath(s string) string {
+	for _, r := range c.redactions {
+		s = r.path.ReplaceAllString(s, "/accounts/"+r.placeholder+"$1")
+	}
+
+	return s
+}
+
+// redactBody replaces account IDs in the ID fields of JSON.
+func (c *Cassette) redactBody(s string) string {
+	for _, r := range c.redactions {
+		s = r.field.ReplaceAllString(s, "${1}"+r.placeholder)
+	}
+
+	return s
+}
+
+// restore puts the cassette's account IDs back in place of placeholders.
+func (c *Cassette) restore(s string) string {
+	for _, r := range c.redactions {
+		s = strings.Replace(s, r.placeholder, r.id, -1)
+	}
+
+	return s
+}
+
+// matches compares requests, ignoring insignificant whitespace in JSON
+// bodies.
+func matches(recorded, req RecordedRequest) bool {
+	if recorded.Method != req.Method || recorded.Path != req.Path {
+		return false
+	}
+
+	return compactJSON(recorded.Body) == compactJSON(req.Body)
+}
+
+func compactJSON(s string) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(s)); err != nil {
+		return s
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,149 @@
+package lctest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassetteRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "orders.json")
+
+	srv := newTestServer(100)
+	defer srv.Close()
+
+	rec := NewRecorder(path, nil, investorID)
+	accounts := srv.Client(lendingclub.WithTransport(rec)).Accounts(investorID)
+
+	_, err = accounts.SubmitOrder([]lendingclub.OrderSubmission{{LoanID: 1, Amount: decimal.New(25, 0)}})
+	require.NoError(t, err)
+	_, err = accounts.AvailableCash()
+	require.NoError(t, err)
+	require.NoError(t, rec.Save())
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "1234")
+	assert.NotContains(t, string(b), "lctest-token")
+
+	cassette, err := Load(path, 5678)
+	require.NoError(t, err)
+
+	c := lendingclub.NewClientWithOptions("other-token",
+		lendingclub.WithBaseURL("http://replay.invalid"),
+		lendingclub.WithTransport(cassette),
+		lendingclub.WithRetryPolicy(nil),
+	)
+	replayed := c.Accounts(5678)
+
+	instruct, err := replayed.SubmitOrder([]lendingclub.OrderSubmission{{LoanID: 1, Amount: decimal.New(25, 0)}})
+	require.NoError(t, err)
+	assert.True(t, instruct.OrderConfirmations[0].ExecutionStatus.Has(lendingclub.OrderFulfilled))
+
+	ac, err := replayed.AvailableCash()
+	require.NoError(t, err)
+	assert.Equal(t, 5678, ac.InvestorID)
+	assert.Equal(t, "75", ac.AvailableCash.String())
+	assert.Empty(t, cassette.Unused())
+
+	// Every interaction is used once.
+	_, err = replayed.AvailableCash()
+	assert.True(t, errors.Is(err, ErrNoInteraction))
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestCassetteRedaction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "redaction.json")
+	rec := NewRecorder(path, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		header := make(http.Header)
+		header.Set("Location", "/accounts/1234/portfolios/1234")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"investorId": 1234, "availableCash": 1234}`)),
+		}, nil
+	}), 1234)
+
+	req, err := http.NewRequest("POST", "http://api.invalid/accounts/1234/loans/12345?limit=1234", strings.NewReader(`{"aid":1234,"loanId":1234}`))
+	require.NoError(t, err)
+	_, err = rec.RoundTrip(req)
+	require.NoError(t, err)
+	require.NoError(t, rec.Save())
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var interactions []Interaction
+	require.NoError(t, json.Unmarshal(b, &interactions))
+	require.Len(t, interactions, 1)
+
+	// Only path segments and ID fields are redacted, not every equal number.
+	in := interactions[0]
+	assert.Equal(t, "/accounts/{{account0}}/loans/12345?limit=1234", in.Request.Path)
+	assert.Equal(t, `{"aid":{{account0}},"loanId":1234}`, in.Request.Body)
+	assert.Equal(t, `{"investorId": {{account0}}, "availableCash": 1234}`, in.Response.Body)
+	assert.Equal(t, "/accounts/{{account0}}/portfolios/1234", in.Response.Header.Get("Location"))
+
+	cassette, err := Load(path, 5678)
+	require.NoError(t, err)
+
+	req, err = http.NewRequest("POST", "http://replay.invalid/accounts/5678/loans/12345?limit=1234", strings.NewReader(`{"aid":5678,"loanId":1234}`))
+	require.NoError(t, err)
+	res, err := cassette.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, "/accounts/5678/portfolios/1234", res.Header.Get("Location"))
+
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"investorId": 5678, "availableCash": 1234}`, string(body))
+}
+
+func TestCassetteFixture(t *testing.T) {
+	cassette, err := Load(filepath.Join("..", CassetteDir, "portfolios.json"), investorID)
+	require.NoError(t, err)
+
+	c := lendingclub.NewClientWithOptions("Token",
+		lendingclub.WithTransport(cassette),
+		lendingclub.WithRetryPolicy(nil),
+	)
+	accounts := c.Accounts(investorID)
+
+	p, err := accounts.CreatePortfolio("Growth", "High grade loans")
+	require.NoError(t, err)
+	assert.Equal(t, "Growth", p.Name)
+
+	_, err = accounts.CreatePortfolio("Growth", "High grade loans")
+	assert.True(t, errors.Is(err, lendingclub.ErrValidation))
+
+	portfolios, err := accounts.Portfolios()
+	require.NoError(t, err)
+	require.Len(t, portfolios, 1)
+	assert.Equal(t, p.ID, portfolios[0].ID)
+
+	_, err = accounts.CreatePortfolio("Income", "")
+	assert.True(t, errors.Is(err, ErrNoInteraction))
+	assert.Empty(t, cassette.Unused())
+}
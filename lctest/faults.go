@@ -0,0 +1,108 @@
+package lctest
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Tonkpils/lendingclub"
+)
+
+// Fault makes the server fail matching requests with an API error instead of
+// handling them.
+type Fault struct {
+	// Method and Path select the requests to fail. An empty Method matches
+	// every method; Path matches as a suffix of the request path, so
+	// "/orders" fails order submissions for every account and an empty Path
+	// matches every request.
+	Method string
+	Path   string
+
+	Status int
+	Errors []lendingclub.APIError
+
+	// Times is the number of requests to fail. Zero fails every matching
+	// request until the faults are cleared.
+	Times int
+}
+
+// Inject adds a fault. Faults are matched in the order they were injected.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.Status == 0 {
+		f.Status = http.StatusInternalServerError
+	}
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes every injected fault.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = d
+}
+
+// Throttle answers with 429 Too Many Requests once more than limit requests
+// arrive within a window of length per. A zero limit turns throttling off.
+func (s *Server) Throttle(limit int, per time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit <= 0 {
+		s.throttle = nil
+		return
+	}
+	s.throttle = &throttle{limit: limit, per: per}
+}
+
+// matchFault returns the first fault matching req and uses it up. It must be
+// called with s.mu held.
+func (s *Server) matchFault(req *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != req.Method {
+			continue
+		}
+		if !strings.HasSuffix(req.URL.Path, f.Path) {
+			continue
+		}
+
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+
+		return f
+	}
+
+	return nil
+}
+
+type throttle struct {
+	limit int
+	per   time.Duration
+	start time.Time
+	count int
+}
+
+func (t *throttle) allow(now time.Time) bool {
+	if now.Sub(t.start) >= t.per {
+		t.start = now
+		t.count = 0
+	}
+	t.count++
+
+	return t.count <= t.limit
+}
@@ -0,0 +1,142 @@
+package lctest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+)
+
+// DelistLoan removes a loan from the listing. Orders for it are rejected as
+// no longer in funding.
+func (s *Server) DelistLoan(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.loans, id)
+	s.touch()
+}
+
+// Orders returns the order instructions an account has submitted.
+func (s *Server) Orders(investorID int) []lendingclub.OrderInstruct {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acct, ok := s.accounts[investorID]
+	if !ok {
+		return nil
+	}
+
+	return append([]lendingclub.OrderInstruct(nil), acct.orders...)
+}
+
+func (h accountHandler) submitOrder(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Orders    []lendingclub.OrderSubmission `json:"orders"`
+		AccountID int                           `json:"aid"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		validationError(w, "", "malformed request: %v", err)
+		return
+	}
+
+	defer h.lock()()
+
+	if body.AccountID != h.acct.investorID {
+		validationError(w, "aid", "account %d does not match investor %d", body.AccountID, h.acct.investorID)
+		return
+	}
+	if len(body.Orders) == 0 {
+		validationError(w, "orders", "no orders submitted")
+		return
+	}
+
+	instruct := lendingclub.OrderInstruct{ID: h.s.newID()}
+	funded := false
+	for _, o := range body.Orders {
+		oc := h.execute(instruct.ID, o)
+		funded = funded || oc.InvestedAmount.Sign() > 0
+		instruct.OrderConfirmations = append(instruct.OrderConfirmations, oc)
+	}
+	if funded {
+		h.s.touch()
+	}
+	h.acct.orders = append(h.acct.orders, instruct)
+
+	writeJSON(w, instruct)
+}
+
+// execute invests in a single loan, funding as much of the requested amount
+// as the loan has left. It must be called with the server locked.
+func (h accountHandler) execute(orderID int, o lendingclub.OrderSubmission) lendingclub.OrderConfirmation {
+	oc := lendingclub.OrderConfirmation{LoanID: o.LoanID, RequestedAmount: o.Amount, InvestedAmount: decimal.Zero}
+	reject := func(status lendingclub.ExecutionStatus) lendingclub.OrderConfirmation {
+		oc.ExecutionStatus = lendingclub.ExecutionStatuses{status}
+		return oc
+	}
+
+	loan, ok := h.s.loans[o.LoanID]
+	if !ok {
+		return reject(lendingclub.OrderNotInFunding)
+	}
+	if o.Amount.Sign() <= 0 || !lendingclub.FloorIncrement(o.Amount).Equals(o.Amount) {
+		return reject(lendingclub.OrderInvalidInvestment)
+	}
+
+	var portfolio *lendingclub.Portfolio
+	if o.PortfolioID != 0 {
+		if portfolio, ok = h.acct.portfolios[o.PortfolioID]; !ok {
+			return reject(lendingclub.OrderInvalidPortfolio)
+		}
+	}
+
+	statuses := lendingclub.ExecutionStatuses{lendingclub.OrderFulfilled}
+	amount := o.Amount
+	remaining := lendingclub.FloorIncrement(loan.LoanAmount.Sub(loan.FundedAmount))
+	if remaining.LessThan(amount) {
+		amount = remaining
+		statuses = append(statuses, lendingclub.OrderLoanAmountExceeded)
+	}
+	if amount.Sign() <= 0 {
+		return reject(lendingclub.OrderNotInFunding)
+	}
+	if h.acct.cash.LessThan(amount) {
+		return reject(lendingclub.OrderInsufficientCash)
+	}
+
+	h.acct.cash = h.acct.cash.Sub(amount)
+	loan.FundedAmount = loan.FundedAmount.Add(amount)
+	loan.InvestorCount++
+
+	note := lendingclub.DetailedNote{
+		ID:                decimal.New(int64(h.s.newID()), 0),
+		LoanID:            decimal.New(int64(loan.ID), 0),
+		OrderID:           decimal.New(int64(orderID), 0),
+		Amount:            amount,
+		LoanAmount:        loan.LoanAmount,
+		LoanLength:        loan.Term,
+		InterestRate:      loan.InterestRate,
+		Grade:             loan.SubGrade,
+		Purpose:           loan.Purpose,
+		LoanStatus:        lendingclub.LoanInFunding,
+		OrderDate:         lendingclub.Time{Time: h.s.now()},
+		PaymentsReceived:  decimal.Zero,
+		PrincipalReceived: decimal.Zero,
+		InterestReceived:  decimal.Zero,
+		LateFeesReceived:  decimal.Zero,
+		PrincipalPending:  amount,
+		InterestPending:   decimal.Zero,
+		AccruedInterest:   decimal.Zero,
+	}
+	if portfolio != nil {
+		note.PortfolioID, note.PortfolioName = portfolio.ID, portfolio.Name
+		statuses = append(statuses, lendingclub.OrderNoteAddedToPortfolio)
+	}
+	h.acct.notes = append(h.acct.notes, note)
+
+	oc.InvestedAmount = amount
+	oc.ExecutionStatus = statuses
+
+	return oc
+}
@@ -0,0 +1,329 @@
+/*
+Package lctest runs an in-memory fake of the Lending Club investor API for
+integration tests.
+
+Unlike a fixture server, the fake keeps its state consistent across calls:
+submitting an order reduces the available cash, funds the loan and creates
+notes; withdrawing funds reserves cash until the transfer settles or is
+cancelled; and so on.
+
+	srv := lctest.NewServer()
+	defer srv.Close()
+
+	srv.AddAccount(1234, decimal.New(1000, 0))
+	srv.ListLoans(loan)
+
+	c := srv.Client()
+	instruct, err := c.Accounts(1234).SubmitOrder(orders)
+
+Errors, latency and throttling can be injected to exercise failure handling.
+
+A Cassette records traffic against the live API (or the fake) once and
+replays it without a network, for deterministic tests of captured responses.
+*/
+package lctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+)
+
+// Server is a fake Lending Club API backed by an httptest.Server.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	accounts map[int]*account
+	loans    map[int]*lendingclub.Loan
+	asOf     time.Time
+	nextID   int
+	faults   []*Fault
+	latency  time.Duration
+	throttle *throttle
+	requests int
+	now      func() time.Time
+}
+
+type account struct {
+	investorID int
+	cash       decimal.Decimal
+	transfers  map[int]*lendingclub.Transfer
+	portfolios map[int]*lendingclub.Portfolio
+	notes      []lendingclub.DetailedNote
+	orders     []lendingclub.OrderInstruct
+}
+
+// NewServer starts a fake API with no accounts and no listed loans.
+func NewServer() *Server {
+	s := &Server{
+		accounts: make(map[int]*account),
+		loans:    make(map[int]*lendingclub.Loan),
+		nextID:   1000,
+		now:      time.Now,
+	}
+	s.asOf = s.now()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// Client returns a client pointed at the server. Retries are disabled so
+// injected faults surface directly; later options override that.
+func (s *Server) Client(opts ...lendingclub.Option) *lendingclub.Client {
+	base := []lendingclub.Option{
+		lendingclub.WithBaseURL(s.URL),
+		lendingclub.WithRetryPolicy(nil),
+	}
+
+	return lendingclub.NewClientWithOptions("lctest-token", append(base, opts...)...)
+}
+
+// AddAccount creates an investor account with the given available cash.
+func (s *Server) AddAccount(investorID int, cash decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accounts[investorID] = &account{
+		investorID: investorID,
+		cash:       cash,
+		transfers:  make(map[int]*lendingclub.Transfer),
+		portfolios: make(map[int]*lendingclub.Portfolio),
+	}
+}
+
+// ListLoans adds loans to the listing, replacing listed loans with the same
+// ID, and moves the listing's as-of date forward.
+func (s *Server) ListLoans(loans ...lendingclub.Loan) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range loans {
+		loan := loans[i]
+		s.loans[loan.ID] = &loan
+	}
+	s.touch()
+}
+
+// Loan returns the listed loan with the given ID as currently funded.
+func (s *Server) Loan(id int) (lendingclub.Loan, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loan, ok := s.loans[id]
+	if !ok {
+		return lendingclub.Loan{}, false
+	}
+
+	return *loan, true
+}
+
+// Cash returns the available cash of an account.
+func (s *Server) Cash(investorID int) decimal.Decimal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if acct, ok := s.accounts[investorID]; ok {
+		return acct.cash
+	}
+
+	return decimal.Zero
+}
+
+// SettleTransfers completes every pending transfer of an account. Deposits
+// become available cash; withdrawn cash leaves the account for good.
+func (s *Server) SettleTransfers(investorID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acct, ok := s.accounts[investorID]
+	if !ok {
+		return
+	}
+
+	for id, t := range acct.transfers {
+		if t.Operation == lendingclub.TransferDeposit {
+			acct.cash = acct.cash.Add(t.Amount)
+		}
+		delete(acct.transfers, id)
+	}
+}
+
+// Requests returns the number of requests the server has received.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// touch moves the listing's as-of date forward. It must be called with s.mu
+// held.
+func (s *Server) touch() {
+	now := s.now()
+	if !now.After(s.asOf) {
+		now = s.asOf.Add(time.Millisecond)
+	}
+	s.asOf = now
+}
+
+func (s *Server) newID() int {
+	s.nextID++
+	return s.nextID
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	latency := s.latency
+	fault := s.matchFault(req)
+	throttled := s.throttle != nil && !s.throttle.allow(s.now())
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-req.Context().Done():
+			return
+		}
+	}
+
+	if throttled {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusTooManyRequests)
+		return
+	}
+
+	if fault != nil {
+		writeError(w, fault.Status, fault.Errors...)
+		return
+	}
+
+	if req.Header.Get("Authorization") == "" {
+		writeError(w, http.StatusUnauthorized)
+		return
+	}
+
+	s.route(w, req)
+}
+
+func (s *Server) route(w http.ResponseWriter, req *http.Request) {
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+
+	if len(parts) == 2 && parts[0] == "loans" && parts[1] == "listing" {
+		s.method(w, req, "GET", s.listing)
+		return
+	}
+
+	if len(parts) < 3 || parts[0] != "accounts" {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+
+	investorID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+
+	s.mu.Lock()
+	acct, ok := s.accounts[investorID]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+
+	h := accountHandler{s: s, acct: acct}
+	switch strings.Join(parts[2:], "/") {
+	case "summary":
+		s.method(w, req, "GET", h.summary)
+	case "availablecash":
+		s.method(w, req, "GET", h.availableCash)
+	case "funds/add":
+		s.method(w, req, "POST", h.addFunds)
+	case "funds/withdraw":
+		s.method(w, req, "POST", h.withdrawFunds)
+	case "funds/pending":
+		s.method(w, req, "GET", h.pendingFunds)
+	case "funds/cancel":
+		s.method(w, req, "POST", h.cancelFunds)
+	case "notes":
+		s.method(w, req, "GET", h.notes)
+	case "detailednotes":
+		s.method(w, req, "GET", h.detailedNotes)
+	case "portfolios":
+		if req.Method == "POST" {
+			h.createPortfolio(w, req)
+			return
+		}
+		s.method(w, req, "GET", h.portfolios)
+	case "orders":
+		s.method(w, req, "POST", h.submitOrder)
+	default:
+		if len(parts) >= 4 && parts[2] == "portfolios" {
+			h.portfolio(w, req, parts[3:])
+			return
+		}
+		writeError(w, http.StatusNotFound)
+	}
+}
+
+func (s *Server) method(w http.ResponseWriter, req *http.Request, method string, h http.HandlerFunc) {
+	if req.Method != method {
+		writeError(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	h(w, req)
+}
+
+func (s *Server) listing(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if since, err := http.ParseTime(req.Header.Get("If-Modified-Since")); err == nil && !s.asOf.Truncate(time.Second).After(since) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	loans := lendingclub.Loans{AsOfDate: lendingclub.Time{Time: s.asOf}}
+	for _, loan := range s.loans {
+		loans.Loans = append(loans.Loans, *loan)
+	}
+	sort.Slice(loans.Loans, func(i, j int) bool { return loans.Loans[i].ID < loans.Loans[j].ID })
+
+	writeJSON(w, loans)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, errs ...lendingclub.APIError) {
+	if len(errs) == 0 {
+		errs = []lendingclub.APIError{{Code: strconv.Itoa(status), Message: http.StatusText(status)}}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(lendingclub.ErrorResponse{Errors: errs})
+}
+
+func validationError(w http.ResponseWriter, field, format string, args ...interface{}) {
+	writeError(w, http.StatusBadRequest, lendingclub.APIError{
+		Field:   field,
+		Code:    "invalid",
+		Message: fmt.Sprintf(format, args...),
+	})
+}
@@ -0,0 +1,224 @@
+package lctest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Tonkpils/lendingclub"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const investorID = 1234
+
+func testLoan(id int, amount, funded int64) lendingclub.Loan {
+	return lendingclub.Loan{
+		ID:           id,
+		Term:         36,
+		Grade:        "B",
+		SubGrade:     "B2",
+		InterestRate: decimal.NewFromFloat(10.99),
+		LoanAmount:   decimal.New(amount, 0),
+		FundedAmount: decimal.New(funded, 0),
+	}
+}
+
+func newTestServer(cash int64) *Server {
+	srv := NewServer()
+	srv.AddAccount(investorID, decimal.New(cash, 0))
+	srv.ListLoans(testLoan(1, 10000, 0), testLoan(2, 1000, 975))
+
+	return srv
+}
+
+func TestSubmitOrder(t *testing.T) {
+	srv := newTestServer(100)
+	defer srv.Close()
+
+	accounts := srv.Client().Accounts(investorID)
+
+	instruct, err := accounts.SubmitOrder([]lendingclub.OrderSubmission{
+		{LoanID: 1, Amount: decimal.New(50, 0)},
+		{LoanID: 2, Amount: decimal.New(50, 0)},
+		{LoanID: 3, Amount: decimal.New(25, 0)},
+		{LoanID: 1, Amount: decimal.New(30, 0)},
+	})
+	require.NoError(t, err)
+	require.Len(t, instruct.OrderConfirmations, 4)
+
+	assert.Equal(t, "ORDER_FULFILLED", instruct.OrderConfirmations[0].ExecutionStatus.String())
+	assert.Equal(t, "50", instruct.OrderConfirmations[0].InvestedAmount.String())
+	assert.Equal(t, "ORDER_FULFILLED,LOAN_AMNT_EXCEEDED", instruct.OrderConfirmations[1].ExecutionStatus.String())
+	assert.Equal(t, "25", instruct.OrderConfirmations[1].InvestedAmount.String())
+	assert.Equal(t, "NOT_AN_IN_FUNDING_LOAN", instruct.OrderConfirmations[2].ExecutionStatus.String())
+	assert.Equal(t, "NOT_A_VALID_INVESTMENT", instruct.OrderConfirmations[3].ExecutionStatus.String())
+
+	totals := instruct.Totals()
+	assert.Equal(t, "75", totals.Invested.String())
+	assert.Equal(t, 1, totals.Partial.Orders)
+	assert.Equal(t, 2, totals.Rejected.Orders)
+
+	ac, err := accounts.AvailableCash()
+	require.NoError(t, err)
+	assert.Equal(t, "25", ac.AvailableCash.String())
+
+	notes, err := accounts.Notes()
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	assert.Equal(t, "50", notes[0].Amount.String())
+	assert.Equal(t, "1", notes[0].LoanID.String())
+	assert.Equal(t, lendingclub.LoanInFunding, notes[0].LoanStatus)
+
+	loan, ok := srv.Loan(1)
+	require.True(t, ok)
+	assert.Equal(t, "50", loan.FundedAmount.String())
+	assert.Equal(t, 1, loan.InvestorCount)
+
+	sum, err := accounts.Summary()
+	require.NoError(t, err)
+	assert.Equal(t, 2, sum.TotalNotes)
+	assert.Equal(t, "75", sum.InFundingBalance.String())
+	assert.Equal(t, "100", sum.AccountTotal.String())
+
+	instruct, err = accounts.SubmitOrder([]lendingclub.OrderSubmission{{LoanID: 1, Amount: decimal.New(50, 0)}})
+	require.NoError(t, err)
+	assert.True(t, instruct.OrderConfirmations[0].ExecutionStatus.Has(lendingclub.OrderInsufficientCash))
+	assert.Len(t, srv.Orders(investorID), 2)
+}
+
+func TestSubmitOrderPortfolio(t *testing.T) {
+	srv := newTestServer(100)
+	defer srv.Close()
+
+	accounts := srv.Client().Accounts(investorID)
+
+	p, err := accounts.CreatePortfolio("Growth", "")
+	require.NoError(t, err)
+
+	_, err = accounts.CreatePortfolio("Growth", "")
+	assert.True(t, errors.Is(err, lendingclub.ErrValidation))
+
+	instruct, err := accounts.SubmitOrder([]lendingclub.OrderSubmission{
+		{LoanID: 1, Amount: decimal.New(25, 0), PortfolioID: p.ID},
+		{LoanID: 1, Amount: decimal.New(25, 0), PortfolioID: 42},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ORDER_FULFILLED,NOTE_ADDED_TO_PORTFOLIO", instruct.OrderConfirmations[0].ExecutionStatus.String())
+	assert.True(t, instruct.OrderConfirmations[1].ExecutionStatus.Has(lendingclub.OrderInvalidPortfolio))
+
+	notes, err := accounts.DetailedNotes()
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "Growth", notes[0].PortfolioName)
+
+	require.NoError(t, accounts.DeletePortfolio(p.ID))
+	portfolios, err := accounts.Portfolios()
+	require.NoError(t, err)
+	assert.Empty(t, portfolios)
+}
+
+func TestFunds(t *testing.T) {
+	srv := newTestServer(100)
+	defer srv.Close()
+
+	accounts := srv.Client().Accounts(investorID)
+
+	_, err := accounts.AddFunds(&lendingclub.FundsPayload{Amount: decimal.New(500, 0), TransferFrequency: lendingclub.LoadNow})
+	require.NoError(t, err)
+	_, err = accounts.WithdrawFunds(decimal.New(40, 0))
+	require.NoError(t, err)
+	_, err = accounts.WithdrawFunds(decimal.New(100, 0))
+	assert.True(t, errors.Is(err, lendingclub.ErrValidation))
+
+	assert.Equal(t, "60", srv.Cash(investorID).String())
+
+	transfers, err := accounts.PendingFunds()
+	require.NoError(t, err)
+	assert.Len(t, transfers, 2)
+
+	withdrawals, err := accounts.PendingFunds(lendingclub.ByOperation(lendingclub.TransferWithdrawal))
+	require.NoError(t, err)
+	require.Len(t, withdrawals, 1)
+
+	cr, err := accounts.CancelFunds([]int{withdrawals[0].TransferID, 99})
+	require.NoError(t, err)
+	require.Len(t, cr.Cancellations, 2)
+	assert.Equal(t, "SUCCESS", cr.Cancellations[0].Status)
+	assert.Equal(t, "FAILED", cr.Cancellations[1].Status)
+	assert.Equal(t, "100", srv.Cash(investorID).String())
+
+	srv.SettleTransfers(investorID)
+	assert.Equal(t, "600", srv.Cash(investorID).String())
+}
+
+func TestListing(t *testing.T) {
+	srv := newTestServer(100)
+	defer srv.Close()
+
+	loans := srv.Client().Loans()
+
+	listed, err := loans.Listed()
+	require.NoError(t, err)
+	require.Len(t, listed.Loans, 2)
+	assert.Equal(t, 1, listed.Loans[0].ID)
+
+	_, err = loans.ListedWithOptions(&lendingclub.ListedOptions{Since: &listed.AsOfDate})
+	assert.Equal(t, lendingclub.ErrNotModified, err)
+}
+
+func TestInjectedFaults(t *testing.T) {
+	srv := newTestServer(100)
+	defer srv.Close()
+
+	srv.Inject(Fault{
+		Method: "POST",
+		Path:   "/orders",
+		Status: http.StatusServiceUnavailable,
+		Times:  1,
+	})
+
+	accounts := srv.Client().Accounts(investorID)
+	orders := []lendingclub.OrderSubmission{{LoanID: 1, Amount: decimal.New(25, 0)}}
+
+	_, err := accounts.SubmitOrder(orders)
+	assert.True(t, errors.Is(err, lendingclub.ErrServer))
+	assert.Equal(t, "100", srv.Cash(investorID).String())
+
+	_, err = accounts.SubmitOrder(orders)
+	require.NoError(t, err)
+	assert.Equal(t, "75", srv.Cash(investorID).String())
+}
+
+func TestThrottle(t *testing.T) {
+	srv := newTestServer(100)
+	defer srv.Close()
+
+	srv.Throttle(1, time.Minute)
+	accounts := srv.Client().Accounts(investorID)
+
+	_, err := accounts.AvailableCash()
+	require.NoError(t, err)
+	_, err = accounts.AvailableCash()
+	assert.True(t, errors.Is(err, lendingclub.ErrTooManyRequests))
+
+	srv.Throttle(0, 0)
+	_, err = accounts.AvailableCash()
+	assert.NoError(t, err)
+}
+
+func TestLatency(t *testing.T) {
+	srv := newTestServer(100)
+	defer srv.Close()
+
+	srv.SetLatency(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := srv.Client().Accounts(investorID).AvailableCashContext(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
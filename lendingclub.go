@@ -2,16 +2,18 @@
 Go wrapper for the Lending Club API.
 
 See https://www.lendingclub.com/developers/lc-api.action for more details on the API.
+
+Every endpoint method has a Context variant (e.g. Summary and SummaryContext)
+that carries the given context.Context down to the HTTP request, so calls can be
+cancelled, given deadlines or traced individually.
 */
 package lendingclub
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"log"
 	"net/http"
 	"time"
 )
@@ -24,86 +26,168 @@ const (
 )
 
 const (
-	lendingClubAPIURL = "https://api.lendingclub.com/api/investor/" + apiVersion
+	lendingClubAPIRoot = "https://api.lendingclub.com/api/investor/"
+	lendingClubAPIURL  = lendingClubAPIRoot + apiVersion
 )
 
 type Client struct {
+	// dryRunIDs counts the synthetic IDs handed out in dry-run mode. It is
+	// updated atomically, so it comes first to stay 64-bit aligned.
+	dryRunIDs int64
+
 	*http.Client
-	authToken string
-	baseURL   string
-}
 
-type ErrorResponse struct {
-	Errors []APIError `json:"errors"`
-}
+	// Limiter, if set, is waited on before every request sent through Do,
+	// including retries.
+	Limiter RateLimiter
+
+	// Retry, if set, controls how failed requests are retried. New clients
+	// use DefaultRetryPolicy.
+	Retry *RetryPolicy
 
-type APIError struct {
-	Field   string `json:"field"`
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	authToken  string
+	baseURL    string
+	apiVersion string
+	userAgent  string
+	logger     Logger
+	dryRun     bool
+
+	timeout   time.Duration
+	transport http.RoundTripper
 }
 
-// NewClient creates a new Client with the given auth token and an optional
-// *http.Client. If the *http.Client is nil, http.DefaultClient will be used.
+// NewClient creates a new Client with the given auth token that sends requests
+// through client, or http.DefaultClient if client is nil.
 func NewClient(authToken string, client *http.Client) *Client {
-	return newClient(lendingClubAPIURL, authToken, client)
+	return NewClientWithOptions(authToken, WithHTTPClient(client))
 }
 
-func newClient(baseURL, authToken string, client *http.Client) *Client {
-	if client == nil {
-		client = http.DefaultClient
+// NewClientWithOptions creates a new Client with the given auth token,
+// configured by the given options. Without options the client talks to the
+// production API using http.DefaultClient, DefaultRetryPolicy and no rate
+// limiting.
+func NewClientWithOptions(authToken string, opts ...Option) *Client {
+	retry := DefaultRetryPolicy
+	c := &Client{
+		Client:     http.DefaultClient,
+		Retry:      &retry,
+		authToken:  authToken,
+		apiVersion: apiVersion,
+		userAgent:  userAgent,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
 	}
 
-	return &Client{
-		Client:    client,
-		baseURL:   baseURL,
-		authToken: authToken,
+	if c.baseURL == "" {
+		c.baseURL = lendingClubAPIRoot + c.apiVersion
+	}
+
+	// Timeouts and transports are set on a copy so a shared *http.Client,
+	// such as http.DefaultClient, is never modified.
+	if c.timeout != 0 || c.transport != nil {
+		hc := *c.Client
+		if c.timeout != 0 {
+			hc.Timeout = c.timeout
+		}
+		if c.transport != nil {
+			hc.Transport = c.transport
+		}
+		c.Client = &hc
+	}
+
+	return c
+}
+
+func newClient(baseURL, authToken string, client *http.Client) *Client {
+	return NewClientWithOptions(authToken, WithBaseURL(baseURL), WithHTTPClient(client))
+}
+
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.logger != nil {
+		c.logger.Printf(format, v...)
 	}
 }
 
-func (c *Client) newRequest(method, urlStr string, body io.Reader) (*http.Request, error) {
-	req, err := http.NewRequest(method, urlStr, body)
+func (c *Client) newRequest(ctx context.Context, method, urlStr string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, body)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("User-Agent", c.userAgent)
 	req.Header.Set("Authorization", c.authToken)
 	req.Header.Set("Content-Type", contentType)
 
 	return req, nil
 }
 
-func debug(bdy io.Reader) {
-	bs, err := ioutil.ReadAll(bdy)
-	if err != nil {
-		log.Fatal(err)
+// Do sends req with the underlying *http.Client, waiting on the Client's
+// Limiter before each attempt and retrying according to its Retry policy.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attempts := c.Retry.attempts(req)
+
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 {
+			r = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+
+		res, err := c.send(r)
+		if attempt >= attempts || ctx.Err() != nil || !shouldRetry(res, err) {
+			return res, err
+		}
+
+		wait := c.Retry.backoff(attempt, res)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return res, err
+		}
+		if res != nil {
+			c.logf("lendingclub: %s %s: %s, retrying in %s", req.Method, req.URL.Path, res.Status, wait)
+			drainBody(res)
+		} else {
+			c.logf("lendingclub: %s %s: %v, retrying in %s", req.Method, req.URL.Path, err, wait)
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.Limiter != nil {
+		if err := c.Limiter.Wait(req.Context(), c.authToken); err != nil {
+			return nil, err
+		}
 	}
 
-	log.Fatal(string(bs))
+	return c.Client.Do(req)
 }
 
 func (c *Client) processResponse(res *http.Response, body interface{}) error {
-	switch res.StatusCode {
-	case http.StatusOK:
-		if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
-			return err
-		}
-	case http.StatusBadRequest:
-		log.Println("BAD REQUEST")
-		debug(res.Body)
-	case http.StatusForbidden:
-		log.Println("FORBIDDEN")
-		debug(res.Body)
-	case http.StatusUnauthorized:
-		return errors.New("unauthorized")
-	case http.StatusNotFound:
-		log.Println("Not Found")
-		debug(res.Body)
-	case http.StatusInternalServerError:
-		return errors.New(res.Status)
-	default:
-		return errors.New("unknown status code " + res.Status)
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return newErrorResponse(res)
+	}
+
+	if body == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(body); err != nil && err != io.EOF {
+		return err
 	}
 
 	return nil
@@ -1,10 +1,18 @@
 package lendingclub
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func respondWithFixture(w http.ResponseWriter, name string) error {
@@ -18,3 +26,23 @@ func respondWithFixture(w http.ResponseWriter, name string) error {
 
 	return nil
 }
+
+func TestContextCancellation(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-done:
+		}
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	ar := newClient(ts.URL, "Token", nil).Accounts(TestAccountID)
+	_, err := ar.SummaryContext(ctx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,85 @@
+package lendingclub
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListed(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/loans/listing", req.RequestURI)
+		assert.Empty(t, req.Header.Get("If-Modified-Since"))
+
+		err := respondWithFixture(w, "listed_loans.json")
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	loans, err := newClient(ts.URL, "Token", nil).Loans().Listed()
+	require.NoError(t, err)
+
+	ti, err := time.Parse(timeFormat, "2015-06-05T14:00:02.123-0700")
+	require.NoError(t, err)
+	assert.Equal(t, ti, loans.AsOfDate.Time)
+
+	require.Len(t, loans.Loans, 2)
+	assert.Equal(t, 50001, loans.Loans[0].ID)
+	assert.Equal(t, SubGrade("A4"), loans.Loans[0].SubGrade)
+	assert.Equal(t, "12.5", loans.Loans[0].DebtToIncome.String())
+	require.NotNil(t, loans.Loans[0].EmploymentLength)
+	assert.Equal(t, 60, *loans.Loans[0].EmploymentLength)
+	assert.Nil(t, loans.Loans[1].EmploymentLength)
+	assert.Nil(t, loans.Loans[1].ReviewStatusDate)
+}
+
+func TestListedWithOptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/loans/listing?filterId=987&showAll=true", req.RequestURI)
+
+		err := respondWithFixture(w, "listed_loans.json")
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	lr := newClient(ts.URL, "Token", nil).Loans()
+	loans, err := lr.ListedWithOptions(&ListedOptions{ShowAll: true, FilterID: 987})
+	require.NoError(t, err)
+	assert.Len(t, loans.Loans, 2)
+}
+
+func TestListedNotModified(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Fri, 05 Jun 2015 21:00:02 GMT", req.Header.Get("If-Modified-Since"))
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer ts.Close()
+
+	since, err := time.Parse(timeFormat, "2015-06-05T14:00:02.123-0700")
+	require.NoError(t, err)
+
+	lr := newClient(ts.URL, "Token", nil).Loans()
+	loans, err := lr.ListedWithOptions(&ListedOptions{Since: &Time{Time: since}})
+	assert.Nil(t, loans)
+	assert.True(t, errors.Is(err, ErrNotModified))
+}
+
+func TestListedUnchangedAsOfDate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		err := respondWithFixture(w, "listed_loans.json")
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	lr := newClient(ts.URL, "Token", nil).Loans()
+	first, err := lr.Listed()
+	require.NoError(t, err)
+
+	_, err = lr.ListedWithOptions(&ListedOptions{Since: &first.AsOfDate})
+	assert.Equal(t, ErrNotModified, err)
+}
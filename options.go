@@ -0,0 +1,99 @@
+package lendingclub
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a Client created by NewClientWithOptions.
+type Option func(*Client)
+
+// Logger is the interface used by a Client to report retries and other
+// non-fatal events. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// WithBaseURL points the client at another API host, such as a local
+// stand-in server. The URL must include any version prefix the host expects;
+// it takes precedence over WithAPIVersion.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithAPIVersion selects the version of the Lending Club investor API.
+func WithAPIVersion(version string) Option {
+	return func(c *Client) {
+		c.apiVersion = version
+	}
+}
+
+// WithUserAgent appends suffix to the User-Agent header sent with every
+// request.
+func WithUserAgent(suffix string) Option {
+	return func(c *Client) {
+		if suffix != "" {
+			c.userAgent = userAgent + " " + suffix
+		}
+	}
+}
+
+// WithHTTPClient sets the *http.Client used to send requests. A nil client
+// leaves http.DefaultClient in place.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		if client != nil {
+			c.Client = client
+		}
+	}
+}
+
+// WithTimeout sets a timeout for each HTTP attempt.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithTransport sets the http.RoundTripper used to send requests.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = rt
+	}
+}
+
+// WithLogger sets the Logger used to report retries.
+func WithLogger(l Logger) Option {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// WithRateLimiter sets the RateLimiter waited on before each request.
+func WithRateLimiter(rl RateLimiter) Option {
+	return func(c *Client) {
+		c.Limiter = rl
+	}
+}
+
+// WithRetryPolicy sets the client's retry policy. A nil policy disables
+// retries.
+func WithRetryPolicy(rp *RetryPolicy) Option {
+	return func(c *Client) {
+		c.Retry = rp
+	}
+}
+
+// WithDryRun makes every state-changing call (orders, fund transfers,
+// portfolio changes and trades) validate its input, log the request it would
+// have sent and return a synthetic result without contacting the API. The
+// requests are logged to the client's Logger, or the standard logger if none
+// is set. Read calls still go to the API.
+func WithDryRun() Option {
+	return func(c *Client) {
+		c.dryRun = true
+	}
+}
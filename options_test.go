@@ -0,0 +1,80 @@
+package lendingclub
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	c := NewClient("Token", nil)
+
+	assert.Equal(t, lendingClubAPIURL, c.baseURL)
+	assert.Equal(t, http.DefaultClient, c.Client)
+	assert.Equal(t, userAgent, c.userAgent)
+	require.NotNil(t, c.Retry)
+	assert.Equal(t, DefaultRetryPolicy, *c.Retry)
+	assert.Nil(t, c.Limiter)
+
+	hc := &http.Client{Timeout: time.Minute}
+	assert.Equal(t, hc, NewClient("Token", hc).Client)
+}
+
+func TestNewClientOptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/accounts/1234/availablecash", req.RequestURI)
+		assert.Equal(t, userAgent+" myapp/1.0", req.Header.Get("User-Agent"))
+		assert.Equal(t, "Token", req.Header.Get("Authorization"))
+
+		err := respondWithFixture(w, "available_cash.json")
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	limiter := NewRateLimiter()
+	c := NewClientWithOptions("Token",
+		WithBaseURL(ts.URL+"/"),
+		WithUserAgent("myapp/1.0"),
+		WithRateLimiter(limiter),
+		WithRetryPolicy(nil),
+	)
+
+	assert.Equal(t, limiter, c.Limiter)
+	assert.Nil(t, c.Retry)
+
+	_, err := c.Accounts(TestAccountID).AvailableCash()
+	require.NoError(t, err)
+}
+
+func TestNewClientAPIVersion(t *testing.T) {
+	c := NewClientWithOptions("Token", WithAPIVersion("v2"))
+	assert.Equal(t, "https://api.lendingclub.com/api/investor/v2", c.baseURL)
+}
+
+func TestNewClientTransportDoesNotModifySharedClient(t *testing.T) {
+	var called bool
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		called = true
+		return nil, http.ErrHandlerTimeout
+	})
+
+	c := NewClientWithOptions("Token", WithTransport(rt), WithTimeout(time.Second), WithRetryPolicy(nil))
+	assert.NotEqual(t, http.DefaultClient, c.Client)
+	assert.Nil(t, http.DefaultClient.Transport)
+	assert.Equal(t, time.Duration(0), http.DefaultClient.Timeout)
+	assert.Equal(t, time.Second, c.Timeout)
+
+	_, err := c.Accounts(TestAccountID).Summary()
+	assert.Error(t, err)
+	assert.True(t, called)
+}
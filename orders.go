@@ -0,0 +1,335 @@
+package lendingclub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// NoteIncrement is the granularity of note amounts accepted by Lending Club.
+var NoteIncrement = decimal.New(25, 0)
+
+// OrderError is a problem with a single order. Index is the order's position
+// in the submission, or -1 for problems with the submission as a whole.
+type OrderError struct {
+	Index  int
+	LoanID int
+	Reason string
+}
+
+func (e OrderError) Error() string {
+	if e.Index < 0 {
+		return e.Reason
+	}
+
+	return fmt.Sprintf("order %d (loan %d): %s", e.Index, e.LoanID, e.Reason)
+}
+
+// OrderErrors lists every problem found in a submission. It matches
+// ErrValidation with errors.Is.
+type OrderErrors []OrderError
+
+func (e OrderErrors) Error() string {
+	errs := make([]string, len(e))
+	for i, oe := range e {
+		errs[i] = oe.Error()
+	}
+
+	return fmt.Sprintf("lendingclub: %d invalid orders: %s", len(e), strings.Join(errs, "; "))
+}
+
+func (e OrderErrors) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// OrderBuilder collects order submissions and checks them against the listed
+// loans, the available cash and, optionally, the investor's portfolios before
+// any money is committed.
+type OrderBuilder struct {
+	loans      map[int]Loan
+	cash       decimal.Decimal
+	portfolios map[int]bool
+	orders     []OrderSubmission
+}
+
+// NewOrderBuilder returns a builder validating against loans and cash.
+func NewOrderBuilder(loans []Loan, cash decimal.Decimal) *OrderBuilder {
+	b := &OrderBuilder{loans: make(map[int]Loan, len(loans)), cash: cash}
+	for _, loan := range loans {
+		b.loans[loan.ID] = loan
+	}
+
+	return b
+}
+
+// OrderBuilder fetches the available cash and portfolios of the account and
+// returns a builder validating against them and loans.
+func (ar *AccountsResource) OrderBuilder(loans []Loan) (*OrderBuilder, error) {
+	return ar.OrderBuilderContext(context.Background(), loans)
+}
+
+func (ar *AccountsResource) OrderBuilderContext(ctx context.Context, loans []Loan) (*OrderBuilder, error) {
+	ac, err := ar.AvailableCashContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolios, err := ar.PortfoliosContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOrderBuilder(loans, ac.AvailableCash).WithPortfolios(portfolios), nil
+}
+
+// WithPortfolios makes the builder reject orders for portfolios other than
+// the given ones. Without it any positive portfolio ID is accepted.
+func (b *OrderBuilder) WithPortfolios(portfolios []Portfolio) *OrderBuilder {
+	b.portfolios = make(map[int]bool, len(portfolios))
+	for _, p := range portfolios {
+		b.portfolios[p.ID] = true
+	}
+
+	return b
+}
+
+// Add appends an order. A zero portfolioID leaves the note unassigned.
+func (b *OrderBuilder) Add(loanID int, amount decimal.Decimal, portfolioID int) *OrderBuilder {
+	b.orders = append(b.orders, OrderSubmission{LoanID: loanID, Amount: amount, PortfolioID: portfolioID})
+	return b
+}
+
+// Orders returns the orders added so far, without validating them.
+func (b *OrderBuilder) Orders() []OrderSubmission {
+	return b.orders
+}
+
+// Build validates the orders and returns them. The error, if any, is an
+// OrderErrors listing every problem found.
+func (b *OrderBuilder) Build() ([]OrderSubmission, error) {
+	if err := b.Validate(b.orders); err != nil {
+		return nil, err
+	}
+
+	return b.orders, nil
+}
+
+// Validate checks orders against the builder's loans, cash and portfolios.
+func (b *OrderBuilder) Validate(orders []OrderSubmission) error {
+	var errs OrderErrors
+	fail := func(i int, o OrderSubmission, format string, args ...interface{}) {
+		errs = append(errs, OrderError{Index: i, LoanID: o.LoanID, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	if len(orders) == 0 {
+		return OrderErrors{{Index: -1, Reason: "no orders"}}
+	}
+
+	total := decimal.Zero
+	seen := make(map[int]int, len(orders))
+	for i, o := range orders {
+		if first, ok := seen[o.LoanID]; ok {
+			fail(i, o, "duplicate of order %d", first)
+		} else {
+			seen[o.LoanID] = i
+		}
+
+		switch {
+		case o.Amount.Sign() <= 0:
+			fail(i, o, "amount must be positive, got %s", o.Amount)
+		case !FloorIncrement(o.Amount).Equals(o.Amount):
+			fail(i, o, "amount %s is not a multiple of %s", o.Amount, NoteIncrement)
+		default:
+			total = total.Add(o.Amount)
+		}
+
+		if loan, ok := b.loans[o.LoanID]; !ok {
+			fail(i, o, "loan is not listed")
+		} else if remaining := loan.LoanAmount.Sub(loan.FundedAmount); remaining.LessThan(o.Amount) {
+			fail(i, o, "amount %s exceeds the %s left to fund", o.Amount, remaining)
+		}
+
+		switch {
+		case o.PortfolioID < 0:
+			fail(i, o, "invalid portfolio ID %d", o.PortfolioID)
+		case o.PortfolioID > 0 && b.portfolios != nil && !b.portfolios[o.PortfolioID]:
+			fail(i, o, "portfolio %d does not exist", o.PortfolioID)
+		}
+	}
+
+	if b.cash.LessThan(total) {
+		errs = append(errs, OrderError{Index: -1, Reason: fmt.Sprintf("orders total %s but only %s is available", total, b.cash)})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// FloorIncrement rounds d down to a multiple of NoteIncrement. Amounts that
+// are not positive round to zero.
+func FloorIncrement(d decimal.Decimal) decimal.Decimal {
+	if d.Sign() <= 0 {
+		return decimal.Zero
+	}
+
+	return d.Div(NoteIncrement).Floor().Mul(NoteIncrement)
+}
+
+// OrderOutcome classifies how much of an order was invested.
+type OrderOutcome int
+
+const (
+	OutcomeRejected OrderOutcome = iota
+	OutcomePartial
+	OutcomeFulfilled
+)
+
+func (o OrderOutcome) String() string {
+	switch o {
+	case OutcomeFulfilled:
+		return "fulfilled"
+	case OutcomePartial:
+		return "partial"
+	default:
+		return "rejected"
+	}
+}
+
+// Outcome reports whether the order was invested in full, in part or not at
+// all.
+func (oc OrderConfirmation) Outcome() OrderOutcome {
+	switch {
+	case oc.InvestedAmount.Sign() <= 0:
+		return OutcomeRejected
+	case oc.InvestedAmount.LessThan(oc.RequestedAmount):
+		return OutcomePartial
+	default:
+		return OutcomeFulfilled
+	}
+}
+
+// Shortfall is the part of the requested amount that was not invested.
+func (oc OrderConfirmation) Shortfall() decimal.Decimal {
+	return oc.RequestedAmount.Sub(oc.InvestedAmount)
+}
+
+// OutcomeTotal sums the orders with the same outcome.
+type OutcomeTotal struct {
+	Orders    int
+	Requested decimal.Decimal
+	Invested  decimal.Decimal
+}
+
+func (ot *OutcomeTotal) add(oc OrderConfirmation) {
+	ot.Orders++
+	ot.Requested = ot.Requested.Add(oc.RequestedAmount)
+	ot.Invested = ot.Invested.Add(oc.InvestedAmount)
+}
+
+// OrderTotals summarizes the confirmations of one or more submissions.
+type OrderTotals struct {
+	Requested decimal.Decimal
+	Invested  decimal.Decimal
+	Fulfilled OutcomeTotal
+	Partial   OutcomeTotal
+	Rejected  OutcomeTotal
+}
+
+// TotalOrders sums confirmations by outcome.
+func TotalOrders(confirmations []OrderConfirmation) OrderTotals {
+	zero := OutcomeTotal{Requested: decimal.Zero, Invested: decimal.Zero}
+	t := OrderTotals{Requested: decimal.Zero, Invested: decimal.Zero, Fulfilled: zero, Partial: zero, Rejected: zero}
+
+	for _, oc := range confirmations {
+		t.Requested = t.Requested.Add(oc.RequestedAmount)
+		t.Invested = t.Invested.Add(oc.InvestedAmount)
+
+		switch oc.Outcome() {
+		case OutcomeFulfilled:
+			t.Fulfilled.add(oc)
+		case OutcomePartial:
+			t.Partial.add(oc)
+		default:
+			t.Rejected.add(oc)
+		}
+	}
+
+	return t
+}
+
+// Totals sums the instruction's confirmations by outcome.
+func (oi *OrderInstruct) Totals() OrderTotals {
+	return TotalOrders(oi.OrderConfirmations)
+}
+
+// PartialFillPolicy resubmits the unfilled part of partially filled orders,
+// sized down to what the loan listing says is left of each loan.
+type PartialFillPolicy struct {
+	// MaxAttempts caps how many times partial fills are resubmitted. Zero
+	// means once.
+	MaxAttempts int
+}
+
+// Resubmit resubmits the partial fills of instruct, the answer to orders,
+// until none are left or the attempts run out. It returns the instructions
+// of the resubmissions.
+func (p *PartialFillPolicy) Resubmit(ctx context.Context, ar *AccountsResource, orders []OrderSubmission, instruct *OrderInstruct) ([]*OrderInstruct, error) {
+	attempts := p.MaxAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	portfolios := make(map[int]int, len(orders))
+	for _, o := range orders {
+		portfolios[o.LoanID] = o.PortfolioID
+	}
+
+	var resubmitted []*OrderInstruct
+	for i := 0; i < attempts; i++ {
+		var partial []OrderConfirmation
+		for _, oc := range instruct.OrderConfirmations {
+			if oc.Outcome() == OutcomePartial {
+				partial = append(partial, oc)
+			}
+		}
+		if len(partial) == 0 {
+			break
+		}
+
+		listed, err := ar.client.Loans().ListedWithOptionsContext(ctx, &ListedOptions{ShowAll: true})
+		if err != nil {
+			return resubmitted, err
+		}
+		remaining := make(map[int]decimal.Decimal, len(listed.Loans))
+		for _, loan := range listed.Loans {
+			remaining[loan.ID] = loan.LoanAmount.Sub(loan.FundedAmount)
+		}
+
+		var retry []OrderSubmission
+		for _, oc := range partial {
+			amount := FloorIncrement(oc.Shortfall())
+			if left := FloorIncrement(remaining[oc.LoanID]); left.LessThan(amount) {
+				amount = left
+			}
+			if amount.Sign() > 0 {
+				retry = append(retry, OrderSubmission{LoanID: oc.LoanID, Amount: amount, PortfolioID: portfolios[oc.LoanID]})
+			}
+		}
+		if len(retry) == 0 {
+			break
+		}
+
+		instruct, err = ar.SubmitOrderContext(ctx, retry)
+		if err != nil {
+			return resubmitted, err
+		}
+		resubmitted = append(resubmitted, instruct)
+	}
+
+	return resubmitted, nil
+}
@@ -0,0 +1,181 @@
+package lendingclub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testListedLoans() []Loan {
+	return []Loan{
+		{ID: 1, LoanAmount: decimal.New(10000, 0), FundedAmount: decimal.New(2000, 0)},
+		{ID: 2, LoanAmount: decimal.New(1000, 0), FundedAmount: decimal.New(975, 0)},
+	}
+}
+
+func TestOrderBuilder(t *testing.T) {
+	orders, err := NewOrderBuilder(testListedLoans(), decimal.New(100, 0)).
+		Add(1, decimal.New(50, 0), 0).
+		Add(2, decimal.New(25, 0), 0).
+		Build()
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	assert.Equal(t, 2, orders[1].LoanID)
+}
+
+func TestOrderBuilderReportsEveryProblem(t *testing.T) {
+	b := NewOrderBuilder(testListedLoans(), decimal.New(75, 0)).
+		WithPortfolios([]Portfolio{{ID: 7, Name: "Growth"}})
+
+	_, err := b.
+		Add(1, decimal.New(30, 0), 0).
+		Add(2, decimal.New(50, 0), 7).
+		Add(3, decimal.New(25, 0), 0).
+		Add(1, decimal.New(25, 0), 8).
+		Build()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	var errs OrderErrors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 6)
+
+	assert.Equal(t, OrderError{Index: 0, LoanID: 1, Reason: "amount 30 is not a multiple of 25"}, errs[0])
+	assert.Equal(t, "amount 50 exceeds the 25 left to fund", errs[1].Reason)
+	assert.Equal(t, "loan is not listed", errs[2].Reason)
+	assert.Equal(t, "duplicate of order 0", errs[3].Reason)
+	assert.Equal(t, "portfolio 8 does not exist", errs[4].Reason)
+	assert.Equal(t, -1, errs[5].Index)
+	assert.Equal(t, "orders total 100 but only 75 is available", errs[5].Reason)
+}
+
+func TestOrderBuilderCash(t *testing.T) {
+	_, err := NewOrderBuilder(testListedLoans(), decimal.New(60, 0)).
+		Add(1, decimal.New(50, 0), 0).
+		Add(2, decimal.New(25, 0), 0).
+		Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "orders total 75 but only 60 is available")
+}
+
+func TestAccountsOrderBuilder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.RequestURI {
+		case fmt.Sprintf("/accounts/%d/availablecash", TestAccountID):
+			fmt.Fprintf(w, `{"investorId": %d, "availableCash": 40}`, TestAccountID)
+		case fmt.Sprintf("/accounts/%d/portfolios", TestAccountID):
+			fmt.Fprint(w, `{"myPortfolios": [{"portfolioId": 7, "portfolioName": "Growth"}]}`)
+		default:
+			t.Errorf("unexpected request %s", req.RequestURI)
+		}
+	}))
+	defer ts.Close()
+
+	ar := newClient(ts.URL, "Token", nil).Accounts(TestAccountID)
+	b, err := ar.OrderBuilder(testListedLoans())
+	require.NoError(t, err)
+
+	_, err = b.Add(1, decimal.New(25, 0), 7).Build()
+	assert.NoError(t, err)
+
+	_, err = b.Add(2, decimal.New(25, 0), 9).Build()
+	var errs OrderErrors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 2)
+	assert.Equal(t, "portfolio 9 does not exist", errs[0].Reason)
+	assert.Equal(t, "orders total 50 but only 40 is available", errs[1].Reason)
+}
+
+func TestOrderConfirmationJSON(t *testing.T) {
+	var instruct OrderInstruct
+	err := json.Unmarshal([]byte(`{
+		"orderInstructId": 55,
+		"orderConfirmations": [
+			{"loanId": 1, "requestedAmount": 50, "investedAmount": 50, "executionStatus": ["ORDER_FULFILLED"]},
+			{"loanId": 2, "requestedAmount": 100, "investedAmount": 25, "executionStatus": ["ORDER_FULFILLED", "LOAN_AMNT_EXCEEDED"]},
+			{"loanId": 3, "requestedAmount": 25, "investedAmount": 0, "executionStatus": "NOT_AN_INFUNDING_LOAN"},
+			{"loanId": 4, "requestedAmount": 75, "investedAmount": 0, "executionStatus": "insufficient_cash, FUTURE_CODE"}
+		]
+	}`), &instruct)
+	require.NoError(t, err)
+
+	ocs := instruct.OrderConfirmations
+	assert.Equal(t, OutcomeFulfilled, ocs[0].Outcome())
+	assert.Equal(t, OutcomePartial, ocs[1].Outcome())
+	assert.True(t, ocs[1].ExecutionStatus.Has(OrderLoanAmountExceeded))
+	assert.Equal(t, "75", ocs[1].Shortfall().String())
+	assert.Equal(t, ExecutionStatuses{OrderNotInFunding}, ocs[2].ExecutionStatus)
+	assert.Equal(t, ExecutionStatuses{OrderInsufficientCash, "FUTURE_CODE"}, ocs[3].ExecutionStatus)
+	assert.Equal(t, OutcomeRejected, ocs[3].Outcome())
+
+	totals := instruct.Totals()
+	assert.Equal(t, "250", totals.Requested.String())
+	assert.Equal(t, "75", totals.Invested.String())
+	assert.Equal(t, 1, totals.Fulfilled.Orders)
+	assert.Equal(t, "100", totals.Partial.Requested.String())
+	assert.Equal(t, "25", totals.Partial.Invested.String())
+	assert.Equal(t, 2, totals.Rejected.Orders)
+	assert.Equal(t, "100", totals.Rejected.Requested.String())
+}
+
+func TestPartialFillPolicy(t *testing.T) {
+	var submitted [][]OrderSubmission
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer req.Body.Close()
+
+		switch req.URL.Path {
+		case "/loans/listing":
+			assert.Equal(t, "true", req.URL.Query().Get("showAll"))
+			fmt.Fprint(w, `{"asOfDate": "2015-06-05T14:00:00.000-0700", "loans": [
+				{"id": 1, "loanAmount": 1000, "fundedAmount": 960},
+				{"id": 2, "loanAmount": 1000, "fundedAmount": 1000}
+			]}`)
+		case fmt.Sprintf("/accounts/%d/orders", TestAccountID):
+			var body struct {
+				Orders    []OrderSubmission `json:"orders"`
+				AccountID int               `json:"aid"`
+			}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			assert.Equal(t, TestAccountID, body.AccountID)
+			submitted = append(submitted, body.Orders)
+
+			fmt.Fprint(w, `{"orderInstructId": 56, "orderConfirmations": [
+				{"loanId": 1, "requestedAmount": 25, "investedAmount": 25, "executionStatus": ["ORDER_FULFILLED"]}
+			]}`)
+		default:
+			t.Errorf("unexpected request %s", req.RequestURI)
+		}
+	}))
+	defer ts.Close()
+
+	ar := newClient(ts.URL, "Token", nil).Accounts(TestAccountID)
+	orders := []OrderSubmission{
+		{LoanID: 1, Amount: decimal.New(100, 0), PortfolioID: 7},
+		{LoanID: 2, Amount: decimal.New(50, 0)},
+	}
+	instruct := &OrderInstruct{ID: 55, OrderConfirmations: []OrderConfirmation{
+		{LoanID: 1, RequestedAmount: decimal.New(100, 0), InvestedAmount: decimal.New(50, 0)},
+		{LoanID: 2, RequestedAmount: decimal.New(50, 0), InvestedAmount: decimal.New(25, 0)},
+	}}
+
+	policy := &PartialFillPolicy{MaxAttempts: 3}
+	resubmitted, err := policy.Resubmit(context.Background(), ar, orders, instruct)
+	require.NoError(t, err)
+	require.Len(t, resubmitted, 1)
+	assert.Equal(t, 56, resubmitted[0].ID)
+
+	// Loan 1 has 40 left, rounded down to 25; loan 2 is fully funded.
+	require.Len(t, submitted, 1)
+	require.Len(t, submitted[0], 1)
+	assert.Equal(t, 1, submitted[0][0].LoanID)
+	assert.Equal(t, "25", submitted[0][0].Amount.String())
+	assert.Equal(t, 7, submitted[0][0].PortfolioID)
+}
@@ -0,0 +1,102 @@
+package lendingclub
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles the requests a Client sends. Wait blocks until a
+// request for the given key may proceed, or returns the context's error if
+// ctx is done first. Clients use their auth token as the key.
+type RateLimiter interface {
+	Wait(ctx context.Context, key string) error
+}
+
+// TokenBucket is a RateLimiter that keeps a separate token bucket per key.
+// It is safe for concurrent use, so a single TokenBucket can be shared by
+// several Clients.
+type TokenBucket struct {
+	interval time.Duration
+	burst    int
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket creates a TokenBucket that allows one request every interval
+// per key, with bursts of up to burst requests.
+func NewTokenBucket(interval time.Duration, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &TokenBucket{
+		interval: interval,
+		burst:    burst,
+		buckets:  make(map[string]*bucket),
+	}
+}
+
+// NewRateLimiter creates a TokenBucket matching Lending Club's limit of one
+// request per second per investor.
+func NewRateLimiter() *TokenBucket {
+	return NewTokenBucket(time.Second, 1)
+}
+
+func (tb *TokenBucket) Wait(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tb.mu.Lock()
+	b := tb.refill(key, time.Now())
+	// Reserve a token up front; a negative balance is the queue of callers
+	// already waiting on this key.
+	b.tokens--
+	wait := time.Duration(-b.tokens * float64(tb.interval))
+	tb.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		tb.mu.Lock()
+		b.tokens++
+		tb.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (tb *TokenBucket) refill(key string, now time.Time) *bucket {
+	b, ok := tb.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(tb.burst), last: now}
+		tb.buckets[key] = b
+		return b
+	}
+
+	if tb.interval > 0 {
+		b.tokens += float64(now.Sub(b.last)) / float64(tb.interval)
+	} else {
+		b.tokens = float64(tb.burst)
+	}
+	if b.tokens > float64(tb.burst) {
+		b.tokens = float64(tb.burst)
+	}
+	b.last = now
+
+	return b
+}
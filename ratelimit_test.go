@@ -0,0 +1,79 @@
+package lendingclub
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucketWait(t *testing.T) {
+	tb := NewTokenBucket(30*time.Millisecond, 1)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, tb.Wait(ctx, "Token"))
+	}
+
+	assert.True(t, time.Since(start) >= 60*time.Millisecond)
+}
+
+func TestTokenBucketSeparateKeys(t *testing.T) {
+	tb := NewTokenBucket(time.Hour, 1)
+	ctx := context.Background()
+
+	require.NoError(t, tb.Wait(ctx, "Token1"))
+	require.NoError(t, tb.Wait(ctx, "Token2"))
+}
+
+func TestTokenBucketContextCancelled(t *testing.T) {
+	tb := NewTokenBucket(time.Hour, 1)
+	require.NoError(t, tb.Wait(context.Background(), "Token"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := tb.Wait(ctx, "Token")
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// The cancelled caller must give its reservation back.
+	assert.InDelta(t, 0, tb.buckets["Token"].tokens, 0.01)
+}
+
+func TestClientUsesLimiter(t *testing.T) {
+	var mu sync.Mutex
+	var hits []time.Time
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		hits = append(hits, time.Now())
+		mu.Unlock()
+
+		err := respondWithFixture(w, "available_cash.json")
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	c := newClient(ts.URL, "Token", nil)
+	c.Limiter = NewTokenBucket(30*time.Millisecond, 1)
+	ar := c.Accounts(TestAccountID)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ar.AvailableCash()
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, hits, 3)
+	assert.True(t, hits[2].Sub(hits[0]) >= 50*time.Millisecond)
+}
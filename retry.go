@@ -0,0 +1,141 @@
+package lendingclub
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how a Client retries requests that failed with a
+// network error, a 429 or a 5xx response.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// A value of 1 or less disables retries.
+	MaxAttempts int
+
+	// MinBackoff and MaxBackoff bound the exponential backoff between
+	// attempts; a zero MaxBackoff leaves it uncapped. A Retry-After header
+	// sent by the API takes precedence, up to MaxBackoff. A wait that would
+	// outlast the request's context deadline is not taken; the last
+	// response is returned instead.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// RetryNonIdempotent allows POST requests, such as SubmitOrder and
+	// AddFunds, to be retried. Retrying them may place an order or move
+	// funds twice, so it is off by default.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy is used by new Clients. It retries idempotent requests
+// up to three times.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+}
+
+func (rp *RetryPolicy) attempts(req *http.Request) int {
+	if rp == nil || rp.MaxAttempts < 1 {
+		return 1
+	}
+
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+	default:
+		if !rp.RetryNonIdempotent {
+			return 1
+		}
+	}
+
+	// A body that cannot be rewound cannot be sent again.
+	if req.Body != nil && req.GetBody == nil {
+		return 1
+	}
+
+	return rp.MaxAttempts
+}
+
+// backoff returns how long to wait before the given retry (1 for the first
+// retry), honouring the response's Retry-After header when present.
+func (rp *RetryPolicy) backoff(retry int, res *http.Response) time.Duration {
+	if res != nil {
+		if d, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			if rp.MaxBackoff > 0 && d > rp.MaxBackoff {
+				d = rp.MaxBackoff
+			}
+			return d
+		}
+	}
+
+	d := rp.MinBackoff
+	for i := 1; i < retry && d < math.MaxInt64/2; i++ {
+		if rp.MaxBackoff > 0 && d >= rp.MaxBackoff {
+			break
+		}
+		d *= 2
+	}
+	if rp.MaxBackoff > 0 && d > rp.MaxBackoff {
+		d = rp.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	// Equal jitter: wait at least half the backoff so retries stay spread out.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+func shouldRetry(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func drainBody(res *http.Response) {
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, maxErrorBodySize))
+	res.Body.Close()
+}
@@ -0,0 +1,170 @@
+package lendingclub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  5 * time.Millisecond,
+}
+
+func flakyServer(failures int32, status int, fixture string) (*httptest.Server, *int32) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&hits, 1) <= failures {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+
+		respondWithFixture(w, fixture)
+	}))
+
+	return ts, &hits
+}
+
+func TestRetryIdempotentRequest(t *testing.T) {
+	ts, hits := flakyServer(2, http.StatusServiceUnavailable, "summary.json")
+	defer ts.Close()
+
+	c := newClient(ts.URL, "Token", nil)
+	policy := testRetryPolicy
+	c.Retry = &policy
+
+	summary, err := c.Accounts(TestAccountID).Summary()
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(hits))
+	assert.Equal(t, 1788402, summary.InvestorID)
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	ts, hits := flakyServer(5, http.StatusInternalServerError, "summary.json")
+	defer ts.Close()
+
+	c := newClient(ts.URL, "Token", nil)
+	policy := testRetryPolicy
+	c.Retry = &policy
+
+	_, err := c.Accounts(TestAccountID).Summary()
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(hits))
+}
+
+func TestRetrySkipsPostByDefault(t *testing.T) {
+	ts, hits := flakyServer(1, http.StatusServiceUnavailable, "withdraw_funds.json")
+	defer ts.Close()
+
+	c := newClient(ts.URL, "Token", nil)
+	policy := testRetryPolicy
+	c.Retry = &policy
+
+	_, err := c.Accounts(TestAccountID).WithdrawFunds(decimal.NewFromFloat(100))
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits))
+}
+
+func TestRetryPostWhenOptedIn(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body struct {
+			Amount decimal.Decimal `json:"amount"`
+		}
+		err := json.NewDecoder(req.Body).Decode(&body)
+		require.NoError(t, err)
+		assert.Equal(t, "100", body.Amount.String())
+
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		respondWithFixture(w, "withdraw_funds.json")
+	}))
+	defer ts.Close()
+
+	c := newClient(ts.URL, "Token", nil)
+	policy := testRetryPolicy
+	policy.RetryNonIdempotent = true
+	c.Retry = &policy
+
+	_, err := c.Accounts(TestAccountID).WithdrawFunds(decimal.NewFromFloat(100))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+}
+
+func TestRetryHonoursRetryAfter(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		respondWithFixture(w, "summary.json")
+	}))
+	defer ts.Close()
+
+	c := newClient(ts.URL, "Token", nil)
+	policy := testRetryPolicy
+	c.Retry = &policy
+
+	// The hour asked for is capped at MaxBackoff.
+	start := time.Now()
+	_, err := c.Accounts(TestAccountID).Summary()
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	assert.True(t, time.Since(start) < time.Second)
+
+	// A wait past the deadline returns the last response straight away.
+	atomic.StoreInt32(&hits, 0)
+	policy.MaxBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start = time.Now()
+	_, err = c.Accounts(TestAccountID).SummaryContext(ctx)
+	assert.True(t, errors.Is(err, ErrTooManyRequests))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+	assert.True(t, time.Since(start) < 500*time.Millisecond)
+}
+
+func TestRetryBackoffUncapped(t *testing.T) {
+	rp := &RetryPolicy{MaxAttempts: 5, MinBackoff: time.Second}
+
+	// Without MaxBackoff the backoff keeps doubling; jitter keeps each wait
+	// between half and all of it.
+	for retry, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second} {
+		d := rp.backoff(retry, nil)
+		assert.True(t, d >= want/2 && d <= want, "retry %d waited %s", retry, d)
+	}
+
+	// Nor is Retry-After capped.
+	res := &http.Response{Header: http.Header{"Retry-After": {"120"}}}
+	assert.Equal(t, 2*time.Minute, rp.backoff(1, res))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	d, ok := parseRetryAfter("3")
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, d)
+
+	d, ok = parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.True(t, d > 59*time.Minute)
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+}
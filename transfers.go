@@ -0,0 +1,260 @@
+package lendingclub
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TransferFilter selects pending transfers.
+type TransferFilter func(Transfer) bool
+
+// ByOperation selects deposits or withdrawals.
+func ByOperation(op TransferOperation) TransferFilter {
+	return func(t Transfer) bool { return t.Operation == op }
+}
+
+// ByStatus selects transfers in the given state.
+func ByStatus(status TransferStatus) TransferFilter {
+	return func(t Transfer) bool { return t.Status == status }
+}
+
+// ByCancellable selects transfers that can, or cannot, be cancelled.
+func ByCancellable(cancellable bool) TransferFilter {
+	return func(t Transfer) bool { return t.Cancellable == cancellable }
+}
+
+func matchTransfer(t Transfer, filters []TransferFilter) bool {
+	for _, f := range filters {
+		if f != nil && !f(t) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CancelPendingFunds cancels every cancellable pending transfer matching all
+// filters. It returns an empty result without calling CancelFunds when no
+// transfer matches.
+func (ar *AccountsResource) CancelPendingFunds(filters ...TransferFilter) (*CancellationResult, error) {
+	return ar.CancelPendingFundsContext(context.Background(), filters...)
+}
+
+func (ar *AccountsResource) CancelPendingFundsContext(ctx context.Context, filters ...TransferFilter) (*CancellationResult, error) {
+	transfers, err := ar.PendingFundsContext(ctx, append(append([]TransferFilter(nil), filters...), ByCancellable(true))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(transfers) == 0 {
+		return &CancellationResult{InvestorID: ar.investorID}, nil
+	}
+
+	ids := make([]int, len(transfers))
+	for i, t := range transfers {
+		ids[i] = t.TransferID
+	}
+
+	return ar.CancelFundsContext(ctx, ids)
+}
+
+// Validate checks the payload against the API's rules: a positive amount, a
+// known frequency, no dates for LOAD_NOW, a start date in the future for
+// every other frequency and, for recurring transfers, an optional end date
+// after the start date.
+func (fp *FundsPayload) Validate() error {
+	return fp.validate(time.Now())
+}
+
+func (fp *FundsPayload) validate(now time.Time) error {
+	if err := fp.TransferFrequency.Validate(); err != nil {
+		return err
+	}
+	if fp.Amount.Sign() <= 0 {
+		return invalid("amount must be positive, got %s", fp.Amount)
+	}
+
+	switch fp.TransferFrequency {
+	case LoadNow:
+		if fp.StartDate != nil || fp.EndDate != nil {
+			return invalid("%s transfers take no start or end date", fp.TransferFrequency)
+		}
+		return nil
+	case LoadOnce:
+		if fp.EndDate != nil {
+			return invalid("%s transfers take no end date", fp.TransferFrequency)
+		}
+	}
+
+	if fp.StartDate == nil {
+		return invalid("%s transfers need a start date", fp.TransferFrequency)
+	}
+	if !fp.StartDate.After(now) {
+		return invalid("start date %s is not in the future", fp.StartDate.Format("2006-01-02"))
+	}
+	if fp.EndDate != nil && !fp.EndDate.After(fp.StartDate.Time) {
+		return invalid("end date %s is not after the start date %s", fp.EndDate.Format("2006-01-02"), fp.StartDate.Format("2006-01-02"))
+	}
+
+	return nil
+}
+
+// DepositNow returns a payload transferring amount immediately.
+func DepositNow(amount decimal.Decimal) (*FundsPayload, error) {
+	fp := &FundsPayload{Amount: amount, TransferFrequency: LoadNow}
+	if err := fp.Validate(); err != nil {
+		return nil, err
+	}
+
+	return fp, nil
+}
+
+// DepositOnce returns a payload transferring amount once, on a future date.
+func DepositOnce(amount decimal.Decimal, on time.Time) (*FundsPayload, error) {
+	fp := &FundsPayload{Amount: amount, TransferFrequency: LoadOnce, StartDate: &Time{Time: on}}
+	if err := fp.Validate(); err != nil {
+		return nil, err
+	}
+
+	return fp, nil
+}
+
+// DepositWeekly returns a payload transferring amount every week from start.
+// A zero end never stops.
+func DepositWeekly(amount decimal.Decimal, start, end time.Time) (*FundsPayload, error) {
+	return NewRecurringDeposit(LoadWeekly, amount, start, end)
+}
+
+// DepositBiweekly returns a payload transferring amount every other week
+// from start. A zero end never stops.
+func DepositBiweekly(amount decimal.Decimal, start, end time.Time) (*FundsPayload, error) {
+	return NewRecurringDeposit(LoadBiweekly, amount, start, end)
+}
+
+// DepositTwiceMonthly returns a payload transferring amount on the 1st and
+// 16th of every month from start. A zero end never stops.
+func DepositTwiceMonthly(amount decimal.Decimal, start, end time.Time) (*FundsPayload, error) {
+	return NewRecurringDeposit(LoadOnDay1And16, amount, start, end)
+}
+
+// DepositMonthly returns a payload transferring amount every month on the
+// day of start. A zero end never stops.
+func DepositMonthly(amount decimal.Decimal, start, end time.Time) (*FundsPayload, error) {
+	return NewRecurringDeposit(LoadMonthly, amount, start, end)
+}
+
+// NewRecurringDeposit returns a validated payload transferring amount at
+// frequency from start until end. A zero end never stops.
+func NewRecurringDeposit(frequency TransferFrequency, amount decimal.Decimal, start, end time.Time) (*FundsPayload, error) {
+	switch frequency {
+	case LoadNow, LoadOnce:
+		return nil, invalid("%s is not a recurring frequency", frequency)
+	}
+
+	fp := &FundsPayload{Amount: amount, TransferFrequency: frequency, StartDate: &Time{Time: start}}
+	if !end.IsZero() {
+		fp.EndDate = &Time{Time: end}
+	}
+	if err := fp.Validate(); err != nil {
+		return nil, err
+	}
+
+	return fp, nil
+}
+
+// Dates returns the dates the payload transfers on, up to and including
+// until. LOAD_NOW payloads have no scheduled date until they are submitted.
+func (fp *FundsPayload) Dates(until time.Time) ([]time.Time, error) {
+	if fp.StartDate == nil {
+		if fp.TransferFrequency == LoadNow {
+			return nil, nil
+		}
+		return nil, invalid("%s transfers need a start date", fp.TransferFrequency)
+	}
+
+	var end time.Time
+	if fp.EndDate != nil {
+		end = fp.EndDate.Time
+	}
+
+	return TransferDates(fp.TransferFrequency, fp.StartDate.Time, end, until)
+}
+
+// Dates returns the dates a pending transfer is due on, up to and including
+// until.
+func (t Transfer) Dates(until time.Time) ([]time.Time, error) {
+	return TransferDates(t.Frequency, t.TransferDate.Time, t.EndDate.Time, until)
+}
+
+// TransferDates expands a transfer starting on start into the dates it is
+// due on, up to and including the earlier of end and until. A zero end never
+// stops. LOAD_NOW and LOAD_ONCE transfers are due on start only. Monthly
+// transfers started late in a month fall on the last day of shorter months.
+// An unknown frequency fails with ErrValidation.
+func TransferDates(frequency TransferFrequency, start, end, until time.Time) ([]time.Time, error) {
+	if !end.IsZero() && end.Before(until) {
+		until = end
+	}
+
+	var dates []time.Time
+	add := func(d time.Time) bool {
+		if d.After(until) {
+			return false
+		}
+		dates = append(dates, d)
+		return true
+	}
+
+	switch frequency {
+	case LoadNow, LoadOnce:
+		add(start)
+	case LoadWeekly, LoadBiweekly:
+		days := 7
+		if frequency == LoadBiweekly {
+			days = 14
+		}
+		for d := start; add(d); d = d.AddDate(0, 0, days) {
+		}
+	case LoadMonthly:
+		for i := 0; add(addMonths(start, i)); i++ {
+		}
+	case LoadOnDay1And16:
+		d := time.Date(start.Year(), start.Month(), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		for {
+			if d.Before(start) {
+				d = nextDay1Or16(d)
+				continue
+			}
+			if !add(d) {
+				break
+			}
+			d = nextDay1Or16(d)
+		}
+	default:
+		return nil, invalid("unknown transfer frequency %q", frequency)
+	}
+
+	return dates, nil
+}
+
+// addMonths adds months to t, clamping the day to the end of the month
+// instead of overflowing into the next one.
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	day := t.Day()
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
+
+func nextDay1Or16(t time.Time) time.Time {
+	if t.Day() < 16 {
+		return t.AddDate(0, 0, 16-t.Day())
+	}
+
+	return time.Date(t.Year(), t.Month()+1, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
@@ -0,0 +1,120 @@
+package lendingclub
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func formatDates(dates []time.Time) []string {
+	s := make([]string, len(dates))
+	for i, d := range dates {
+		s[i] = d.Format("2006-01-02")
+	}
+
+	return s
+}
+
+func TestDepositConstructors(t *testing.T) {
+	start := time.Now().AddDate(0, 0, 3)
+	amount := decimal.New(100, 0)
+
+	fp, err := DepositNow(amount)
+	require.NoError(t, err)
+	assert.Equal(t, LoadNow, fp.TransferFrequency)
+	assert.Nil(t, fp.StartDate)
+
+	fp, err = DepositOnce(amount, start)
+	require.NoError(t, err)
+	assert.Equal(t, LoadOnce, fp.TransferFrequency)
+	assert.Nil(t, fp.EndDate)
+
+	fp, err = DepositMonthly(amount, start, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, LoadMonthly, fp.TransferFrequency)
+	assert.Equal(t, start, fp.StartDate.Time)
+	assert.Nil(t, fp.EndDate)
+
+	fp, err = DepositTwiceMonthly(amount, start, start.AddDate(1, 0, 0))
+	require.NoError(t, err)
+	assert.Equal(t, LoadOnDay1And16, fp.TransferFrequency)
+	assert.Equal(t, start.AddDate(1, 0, 0), fp.EndDate.Time)
+
+	for name, f := range map[string]func() (*FundsPayload, error){
+		"past start":   func() (*FundsPayload, error) { return DepositWeekly(amount, time.Now().AddDate(0, 0, -1), time.Time{}) },
+		"end first":    func() (*FundsPayload, error) { return DepositBiweekly(amount, start, start.AddDate(0, 0, -1)) },
+		"zero amount":  func() (*FundsPayload, error) { return DepositOnce(decimal.Zero, start) },
+		"not periodic": func() (*FundsPayload, error) { return NewRecurringDeposit(LoadOnce, amount, start, time.Time{}) },
+		"unknown":      func() (*FundsPayload, error) { return NewRecurringDeposit("LOAD_YEARLY", amount, start, time.Time{}) },
+	} {
+		_, err := f()
+		assert.True(t, errors.Is(err, ErrValidation), name)
+	}
+}
+
+func TestFundsPayloadValidate(t *testing.T) {
+	now := day(2015, 6, 5)
+	start := &Time{Time: day(2015, 7, 1)}
+
+	assert.NoError(t, (&FundsPayload{Amount: decimal.New(1, 0), TransferFrequency: LoadWeekly, StartDate: start}).validate(now))
+
+	err := (&FundsPayload{Amount: decimal.New(1, 0), TransferFrequency: LoadNow, StartDate: start}).validate(now)
+	assert.EqualError(t, err, "lendingclub: validation failed: LOAD_NOW transfers take no start or end date")
+
+	err = (&FundsPayload{Amount: decimal.New(1, 0), TransferFrequency: LoadMonthly}).validate(now)
+	assert.EqualError(t, err, "lendingclub: validation failed: LOAD_MONTHLY transfers need a start date")
+
+	err = (&FundsPayload{Amount: decimal.New(1, 0), TransferFrequency: LoadOnce, StartDate: start, EndDate: start}).validate(now)
+	assert.EqualError(t, err, "lendingclub: validation failed: LOAD_ONCE transfers take no end date")
+}
+
+func TestTransferDates(t *testing.T) {
+	until := day(2016, 4, 1)
+	dates := func(freq TransferFrequency, start, end, until time.Time) []string {
+		ds, err := TransferDates(freq, start, end, until)
+		require.NoError(t, err)
+		return formatDates(ds)
+	}
+
+	weekly := dates(LoadWeekly, day(2016, 1, 1), time.Time{}, day(2016, 1, 29))
+	assert.Equal(t, []string{"2016-01-01", "2016-01-08", "2016-01-15", "2016-01-22", "2016-01-29"}, weekly)
+
+	biweekly := dates(LoadBiweekly, day(2016, 1, 1), day(2016, 2, 1), until)
+	assert.Equal(t, []string{"2016-01-01", "2016-01-15", "2016-01-29"}, biweekly)
+
+	monthly := dates(LoadMonthly, day(2016, 1, 31), time.Time{}, until)
+	assert.Equal(t, []string{"2016-01-31", "2016-02-29", "2016-03-31"}, monthly)
+
+	twice := dates(LoadOnDay1And16, day(2016, 1, 10), time.Time{}, day(2016, 3, 1))
+	assert.Equal(t, []string{"2016-01-16", "2016-02-01", "2016-02-16", "2016-03-01"}, twice)
+
+	assert.Equal(t, []string{"2016-01-10"}, dates(LoadOnce, day(2016, 1, 10), time.Time{}, until))
+	assert.Empty(t, dates(LoadOnce, day(2016, 5, 10), time.Time{}, until))
+	assert.Equal(t, []string{"2016-01-10"}, dates(LoadNow, day(2016, 1, 10), time.Time{}, until))
+
+	_, err := TransferDates("LOAD_YEARLY", day(2016, 1, 10), time.Time{}, until)
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	fp := &FundsPayload{Amount: decimal.New(50, 0), TransferFrequency: LoadMonthly, StartDate: &Time{Time: day(2016, 1, 5)}}
+	ds, err := fp.Dates(until)
+	require.NoError(t, err)
+	assert.Len(t, ds, 3)
+
+	tr := Transfer{Frequency: LoadWeekly, TransferDate: Time{Time: day(2016, 3, 18)}, EndDate: Time{Time: day(2016, 3, 25)}}
+	ds, err = tr.Dates(until)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2016-03-18", "2016-03-25"}, formatDates(ds))
+
+	tr = Transfer{Frequency: LoadNow, TransferDate: Time{Time: day(2016, 3, 18)}}
+	ds, err = tr.Dates(until)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2016-03-18"}, formatDates(ds))
+}
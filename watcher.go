@@ -0,0 +1,225 @@
+package lendingclub
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LoanEventType tells whether a LoanEvent is about a new or a changed loan.
+type LoanEventType int
+
+const (
+	// LoanListed is sent the first time a LoanWatcher sees a loan.
+	LoanListed LoanEventType = iota + 1
+	// LoanUpdated is sent when a loan's funded amount or investor count
+	// changes.
+	LoanUpdated
+)
+
+func (t LoanEventType) String() string {
+	switch t {
+	case LoanListed:
+		return "listed"
+	case LoanUpdated:
+		return "updated"
+	}
+
+	return "unknown"
+}
+
+type LoanEvent struct {
+	Type LoanEventType
+	Loan Loan
+	// Previous is the loan as last seen, for LoanUpdated events.
+	Previous *Loan
+}
+
+// WatchSchedule controls how often a LoanWatcher polls. Polling happens every
+// Interval, except within BurstWindow of one of the ReleaseTimes, when it
+// happens every BurstInterval.
+type WatchSchedule struct {
+	Interval time.Duration
+
+	// ReleaseTimes are wall-clock times of day, in Location, at which
+	// Lending Club releases new loans.
+	ReleaseTimes []time.Duration
+	Location     *time.Location
+
+	BurstWindow   time.Duration
+	BurstInterval time.Duration
+}
+
+// DefaultWatchSchedule polls every five minutes, and every two seconds for two
+// minutes either side of Lending Club's release times (6am, 10am, 2pm and 6pm
+// Pacific).
+var DefaultWatchSchedule = WatchSchedule{
+	Interval:      5 * time.Minute,
+	ReleaseTimes:  []time.Duration{6 * time.Hour, 10 * time.Hour, 14 * time.Hour, 18 * time.Hour},
+	Location:      pacificTime(),
+	BurstWindow:   2 * time.Minute,
+	BurstInterval: 2 * time.Second,
+}
+
+func pacificTime() *time.Location {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		// Without tzdata the release times drift by an hour during DST,
+		// which the burst window mostly absorbs.
+		return time.FixedZone("PST", -8*60*60)
+	}
+
+	return loc
+}
+
+// next returns how long to wait after now before polling again.
+func (s WatchSchedule) next(now time.Time) time.Duration {
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+
+	wait := s.Interval
+	if wait <= 0 {
+		wait = DefaultWatchSchedule.Interval
+	}
+	if s.BurstInterval <= 0 || len(s.ReleaseTimes) == 0 {
+		return wait
+	}
+
+	// Yesterday's and tomorrow's releases matter around midnight. Releases
+	// are built from wall-clock times so they stay put on DST transitions.
+	for day := -1; day <= 1; day++ {
+		for _, rt := range s.ReleaseTimes {
+			release := time.Date(now.Year(), now.Month(), now.Day()+day,
+				int(rt/time.Hour), int(rt%time.Hour/time.Minute), int(rt%time.Minute/time.Second), 0, loc)
+			start, end := release.Add(-s.BurstWindow), release.Add(s.BurstWindow)
+
+			if !now.Before(start) && !now.After(end) {
+				return s.BurstInterval
+			}
+			if now.Before(start) && start.Sub(now) < wait {
+				wait = start.Sub(now)
+			}
+		}
+	}
+
+	return wait
+}
+
+// LoanWatcher polls the listed loans and reports loans it has not seen
+// before, as well as changes to the funding of loans it has seen.
+type LoanWatcher struct {
+	Schedule WatchSchedule
+
+	// Options are used for every poll. ShowAll is always set and Since is
+	// ignored, so funding changes between releases are seen.
+	Options ListedOptions
+
+	// OnError, if set, is called with polling errors. Otherwise they are
+	// reported through the client's Logger. The watcher keeps polling after
+	// an error.
+	OnError func(error)
+
+	loans *LoansResource
+	now   func() time.Time
+
+	mu   sync.Mutex
+	seen map[int]Loan
+}
+
+// Watcher creates a LoanWatcher using DefaultWatchSchedule that asks for every
+// listed loan.
+func (lr *LoansResource) Watcher() *LoanWatcher {
+	return &LoanWatcher{
+		Schedule: DefaultWatchSchedule,
+		Options:  ListedOptions{ShowAll: true},
+		loans:    lr,
+		now:      time.Now,
+		seen:     make(map[int]Loan),
+	}
+}
+
+// Poll fetches the listed loans once and returns the events since the
+// previous poll.
+func (w *LoanWatcher) Poll(ctx context.Context) ([]LoanEvent, error) {
+	// Every poll asks for the full listing and diffs it by loan ID: the
+	// listing's as-of date only moves at releases, while funding changes
+	// all the time.
+	opts := w.Options
+	opts.ShowAll = true
+	opts.Since = nil
+
+	loans, err := w.loans.ListedWithOptionsContext(ctx, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var events []LoanEvent
+	for _, loan := range loans.Loans {
+		prev, ok := w.seen[loan.ID]
+		w.seen[loan.ID] = loan
+
+		switch {
+		case !ok:
+			events = append(events, LoanEvent{Type: LoanListed, Loan: loan})
+		case prev.FundedAmount.Cmp(loan.FundedAmount) != 0 || prev.InvestorCount != loan.InvestorCount:
+			prev := prev
+			events = append(events, LoanEvent{Type: LoanUpdated, Loan: loan, Previous: &prev})
+		}
+	}
+
+	// Forget loans that can no longer be invested in.
+	now := w.now()
+	for id, loan := range w.seen {
+		if !loan.ExpireDate.IsZero() && loan.ExpireDate.Before(now) {
+			delete(w.seen, id)
+		}
+	}
+
+	return events, nil
+}
+
+// Run polls on the watcher's schedule and calls handle for every event until
+// ctx is done, at which point it returns ctx.Err().
+func (w *LoanWatcher) Run(ctx context.Context, handle func(LoanEvent)) error {
+	for {
+		events, err := w.Poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			if w.OnError != nil {
+				w.OnError(err)
+			} else {
+				w.loans.client.logf("lendingclub: polling listed loans: %v", err)
+			}
+		}
+		for _, e := range events {
+			handle(e)
+		}
+
+		if err := sleepContext(ctx, w.Schedule.next(w.now())); err != nil {
+			return err
+		}
+	}
+}
+
+// Watch runs the watcher in a new goroutine and returns a channel with its
+// events. The channel is closed once ctx is done.
+func (w *LoanWatcher) Watch(ctx context.Context) <-chan LoanEvent {
+	ch := make(chan LoanEvent)
+
+	go func() {
+		defer close(ch)
+		w.Run(ctx, func(e LoanEvent) {
+			select {
+			case ch <- e:
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	return ch
+}
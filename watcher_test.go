@@ -0,0 +1,226 @@
+package lendingclub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listingServer serves the listed_loans.json fixture on the first request and
+// a later listing, in which loan 50001 gained an investor and loan 50003 was
+// added, on every request after that.
+func listingServer(t *testing.T) *httptest.Server {
+	f, err := os.Open(filepath.Join("./fixtures", "listed_loans.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var first map[string]interface{}
+	require.NoError(t, json.NewDecoder(f).Decode(&first))
+
+	later, err := json.Marshal(first)
+	require.NoError(t, err)
+	var second map[string]interface{}
+	require.NoError(t, json.Unmarshal(later, &second))
+
+	second["asOfDate"] = "2015-06-05T14:05:00.000-0700"
+	loans := second["loans"].([]interface{})
+	updated := loans[0].(map[string]interface{})
+	updated["fundedAmount"] = 2525
+	updated["investorCount"] = 13
+	added := make(map[string]interface{})
+	for k, v := range loans[1].(map[string]interface{}) {
+		added[k] = v
+	}
+	added["id"] = 50003
+	second["loans"] = append(loans, added)
+
+	var hits int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/loans/listing?showAll=true", req.RequestURI)
+
+		if atomic.AddInt32(&hits, 1) == 1 {
+			json.NewEncoder(w).Encode(first)
+			return
+		}
+		json.NewEncoder(w).Encode(second)
+	}))
+}
+
+func testWatcher(url string) *LoanWatcher {
+	w := newClient(url, "Token", nil).Loans().Watcher()
+	w.now = func() time.Time {
+		return time.Date(2015, 6, 5, 21, 5, 0, 0, time.UTC)
+	}
+
+	return w
+}
+
+func TestLoanWatcherPoll(t *testing.T) {
+	ts := listingServer(t)
+	defer ts.Close()
+
+	w := testWatcher(ts.URL)
+	ctx := context.Background()
+
+	events, err := w.Poll(ctx)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, LoanListed, events[0].Type)
+	assert.Equal(t, 50001, events[0].Loan.ID)
+	assert.Equal(t, 50002, events[1].Loan.ID)
+
+	events, err = w.Poll(ctx)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, LoanUpdated, events[0].Type)
+	assert.Equal(t, 50001, events[0].Loan.ID)
+	assert.Equal(t, 13, events[0].Loan.InvestorCount)
+	require.NotNil(t, events[0].Previous)
+	assert.Equal(t, 12, events[0].Previous.InvestorCount)
+	assert.Equal(t, "2500", events[0].Previous.FundedAmount.String())
+
+	assert.Equal(t, LoanListed, events[1].Type)
+	assert.Equal(t, 50003, events[1].Loan.ID)
+
+	events, err = w.Poll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestLoanWatcherPollSameListing(t *testing.T) {
+	f, err := os.Open(filepath.Join("./fixtures", "listed_loans.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var listing map[string]interface{}
+	require.NoError(t, json.NewDecoder(f).Decode(&listing))
+
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Empty(t, req.Header.Get("If-Modified-Since"))
+
+		// Funding moves between releases without the as-of date changing.
+		if atomic.AddInt32(&hits, 1) > 1 {
+			listing["loans"].([]interface{})[1].(map[string]interface{})["fundedAmount"] = 5000
+		}
+		json.NewEncoder(w).Encode(listing)
+	}))
+	defer ts.Close()
+
+	w := testWatcher(ts.URL)
+	w.Options = ListedOptions{}
+
+	_, err = w.Poll(context.Background())
+	require.NoError(t, err)
+
+	events, err := w.Poll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, LoanUpdated, events[0].Type)
+	assert.Equal(t, 50002, events[0].Loan.ID)
+	assert.Equal(t, "5000", events[0].Loan.FundedAmount.String())
+}
+
+func TestLoanWatcherLogsErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	c := NewClientWithOptions("Token", WithBaseURL(ts.URL), WithRetryPolicy(nil), WithLogger(log.New(&logs, "", 0)))
+	w := c.Loans().Watcher()
+	w.Schedule = WatchSchedule{Interval: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, w.Run(ctx, func(LoanEvent) {}))
+	assert.Contains(t, logs.String(), "lendingclub: polling listed loans: lendingclub: GET /loans/listing: 403 Forbidden")
+}
+
+func TestLoanWatcherWatch(t *testing.T) {
+	ts := listingServer(t)
+	defer ts.Close()
+
+	w := testWatcher(ts.URL)
+	w.Schedule = WatchSchedule{Interval: 10 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := w.Watch(ctx)
+
+	var got []LoanEvent
+	for e := range events {
+		got = append(got, e)
+		if len(got) == 4 {
+			cancel()
+		}
+	}
+
+	require.Len(t, got, 4)
+	assert.Equal(t, LoanUpdated, got[2].Type)
+	assert.Equal(t, 50003, got[3].Loan.ID)
+}
+
+func TestWatchScheduleNext(t *testing.T) {
+	loc := time.FixedZone("PDT", -7*60*60)
+	s := WatchSchedule{
+		Interval:      5 * time.Minute,
+		ReleaseTimes:  []time.Duration{6 * time.Hour, 18 * time.Hour},
+		Location:      loc,
+		BurstWindow:   time.Minute,
+		BurstInterval: time.Second,
+	}
+
+	at := func(hour, min, sec int) time.Time {
+		return time.Date(2015, 6, 5, hour, min, sec, 0, loc)
+	}
+
+	assert.Equal(t, 5*time.Minute, s.next(at(12, 0, 0)))
+	assert.Equal(t, 2*time.Minute, s.next(at(5, 57, 0)))
+	assert.Equal(t, time.Second, s.next(at(5, 59, 30)))
+	assert.Equal(t, time.Second, s.next(at(6, 1, 0)))
+	assert.Equal(t, 5*time.Minute, s.next(at(6, 1, 1)))
+	assert.Equal(t, time.Second, s.next(at(18, 0, 0).UTC()))
+}
+
+func TestWatchScheduleNextDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+
+	s := WatchSchedule{
+		Interval:      5 * time.Minute,
+		ReleaseTimes:  []time.Duration{6 * time.Hour},
+		Location:      loc,
+		BurstWindow:   time.Minute,
+		BurstInterval: time.Second,
+	}
+
+	// Clocks spring forward on 2015-03-08 and fall back on 2015-11-01; the
+	// release is still at 6am local time on both days.
+	for _, d := range []int{8, 1} {
+		month := time.March
+		if d == 1 {
+			month = time.November
+		}
+		release := time.Date(2015, month, d, 6, 0, 0, 0, loc)
+
+		assert.Equal(t, time.Second, s.next(release), release)
+		assert.Equal(t, 2*time.Minute, s.next(release.Add(-3*time.Minute)), release)
+		assert.Equal(t, 5*time.Minute, s.next(release.Add(-time.Hour)), release)
+	}
+}